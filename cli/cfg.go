@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gaoliveira21/chip8/cli/debug"
+)
+
+// controlFlowGraph runs the cfg command, it prints the control flow graph
+// of a ROM in the DOT language of Graphviz and returns the exit status.
+func controlFlowGraph(args []string) int {
+	a, err := analyze("cfg", "Prints the control flow graph of a ROM for Graphviz, e.g. chip8 cfg rom.ch8 | dot -Tsvg > rom.svg", args)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if err := debug.NewCFG(a.rom, a.start).WriteDOT(os.Stdout, a.symbols); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	return 0
+}
@@ -7,19 +7,224 @@ import (
 
 	"github.com/gaoliveira21/chip8/cli/debug"
 	"github.com/gaoliveira21/chip8/core"
+	"github.com/gaoliveira21/chip8/core/config"
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/dap"
+	"github.com/gaoliveira21/chip8/core/disasm"
+	"github.com/gaoliveira21/chip8/core/gdb"
 )
 
 func main() {
-	rom := flag.String("rom", "", "ROM path")
+	if len(os.Args) > 1 && os.Args[1] == "trace-diff" {
+		os.Exit(traceDiff(os.Args[2:]))
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(lint(os.Args[2:]))
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "cfg" {
+		os.Exit(controlFlowGraph(os.Args[2:]))
+	}
+
+	os.Exit(run())
+}
+
+// run starts the emulator and returns the exit status, once the profile and
+// the trace are written.
+func run() int {
+
+	rom := flag.String("rom", "", "ROM path, the ROM browser opens when empty")
+	roms := flag.String("roms", "", "Directory opened by the ROM browser, overrides the configuration file")
+	configPath := flag.String("config", "chip8.json", "Configuration file path")
+	platform := flag.String("platform", "", "Platform (chip8, chip8e, chip8x, chip10, schip, megachip), overrides the configuration file")
+	tracePath := flag.String("trace", "", "Write a trace of every instruction run to this file")
+	traceFormat := flag.String("trace-format", cpu.TRACE_TEXT, "Trace format (text, binary)")
+	traceRange := flag.String("trace-range", "", "Only trace instructions in these address ranges, e.g. 200-2FF,400-4FF")
+	specs := []string{}
+	flag.Func("break", "Stop at a breakpoint, e.g. 204, draw+2, \"write 300-30F\", \"if VA == 0x10 && I > 0x300\", \"204 hits 3\" (repeatable)", func(spec string) error {
+		specs = append(specs, spec)
+		return nil
+	})
+	symbolPaths := []string{}
+	flag.Func("symbols", "Load labels and source lines from a symbol file, e.g. game.sym (repeatable)", func(path string) error {
+		symbolPaths = append(symbolPaths, path)
+		return nil
+	})
+	profilePath := flag.String("profile", "", "Write a pprof profile of the instructions run to this file on exit, and a report to the standard output")
+	callLog := flag.Int("call-log", 0, "Keep the last N subroutine calls and returns, shown by the debug overlay at breakpoints")
+	dapAddr := flag.String("dap", "", "Serve the Debug Adapter Protocol on this loopback address, e.g. localhost:4711")
+	gdbAddr := flag.String("gdb", "", "Serve the GDB remote protocol on this loopback address, e.g. localhost:1234")
 	flag.Parse()
 
-	romData, err := os.ReadFile(*rom)
+	tools := core.DebugOptions{}
+	var err error
+
+	if len(symbolPaths) > 0 {
+		if tools.Symbols, err = disasm.LoadSymbols(symbolPaths...); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	breakpoints := &cpu.Breakpoints{}
+
+	for _, spec := range specs {
+		b, err := tools.Symbols.ParseBreakpoint(spec)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		breakpoints.Add(b)
+	}
+
+	var romData []byte
+
+	if *rom != "" {
+		data, err := os.ReadFile(*rom)
 
-	go debug.NewDebugger(romData, *rom)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		romData = data
+	}
+
+	cfg, err := config.Load(*configPath)
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	core.RunChip8(romData, *rom)
+	if *platform != "" {
+		cfg.Platform = *platform
+	}
+
+	if *roms != "" {
+		cfg.Roms = *roms
+	}
+
+	if romData != nil {
+		p, err := cpu.ParsePlatform(cfg.Platform)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go debug.NewDebugger(romData, *rom, p.StartAddress(), tools.Symbols)
+	}
+
+	if len(breakpoints.List) > 0 || *gdbAddr != "" || *dapAddr != "" {
+		tools.Breakpoints = breakpoints
+	}
+
+	if *callLog > 0 {
+		tools.Calls = cpu.NewCallLog(*callLog)
+	}
+
+	if *gdbAddr != "" {
+		s, err := gdb.Listen(*gdbAddr, nil)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		defer s.Close()
+		tools.Debuggers = append(tools.Debuggers, s)
+		log.Printf("GDB server listening on %s", s.Addr())
+	}
+
+	if *dapAddr != "" {
+		s, err := dap.Listen(*dapAddr)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		defer s.Close()
+		tools.Debuggers = append(tools.Debuggers, s)
+		log.Printf("DAP server listening on %s", s.Addr())
+	}
+
+	if *tracePath != "" {
+		tools.Trace, err = newTrace(*tracePath, *traceFormat, *traceRange)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		defer tools.Trace.Close()
+	}
+
+	if *profilePath != "" {
+		tools.Profile = cpu.NewProfile()
+		tools.Profile.Mnemonic = disasm.Mnemonic
+		tools.Profile.Name = func(addr uint16) string {
+			label, _ := tools.Symbols.Label(addr)
+			return label
+		}
+	}
+
+	status := 0
+
+	if err := core.RunChip8(romData, *rom, cfg, tools); err != nil {
+		log.Print(err)
+		status = 1
+	}
+
+	if tools.Profile != nil {
+		if err := writeProfile(*profilePath, tools.Profile); err != nil {
+			log.Print(err)
+			status = 1
+		}
+	}
+
+	return status
+}
+
+// writeProfile writes the pprof profile to path and the text report to the
+// standard output.
+func writeProfile(path string, p *cpu.Profile) error {
+	f, err := os.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	if err := p.WritePprof(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return p.WriteText(os.Stdout)
+}
+
+func newTrace(path string, format string, ranges string) (*cpu.Trace, error) {
+	r, err := cpu.ParseTraceRanges(ranges)
+
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	trace, err := cpu.NewTrace(f, format)
+
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	trace.Ranges = r
+	trace.Mnemonic = disasm.Mnemonic
+
+	return trace, nil
 }
@@ -0,0 +1,276 @@
+package debug
+
+import (
+	"sort"
+
+	"github.com/gaoliveira21/chip8/core/disasm"
+)
+
+// Kinds of control flow edges.
+const (
+	EDGE_NEXT  = iota // The following instruction
+	EDGE_JUMP         // 1NNN, and 0NNN which the CPU runs as a jump
+	EDGE_CALL         // 2NNN, the block also continues after the call
+	EDGE_SKIP         // Skip instructions, over the following instruction
+	EDGE_TABLE        // BNNN into a table of jumps
+)
+
+// JUMP_TABLE_SIZE is the most entries followed in the table of a BNNN.
+const JUMP_TABLE_SIZE = 128
+
+type Edge struct {
+	To   uint16
+	Kind int
+}
+
+// Instruction is an instruction reached by the control flow.
+type Instruction struct {
+	Addr    uint16
+	Opcode  uint16
+	Size    uint16 // 4 for the XO-CHIP F000 NNNN
+	Long    uint16 // NNNN of F000 NNNN
+	Valid   bool   // False for data reached as code
+	Edges   []Edge // Instructions that can run next
+	Outside bool   // Past the end of the ROM
+}
+
+// Block is a basic block, instructions that always run in sequence.
+type Block struct {
+	Start        uint16
+	End          uint16 // Address after the last instruction
+	Instructions []*Instruction
+	Edges        []Edge // Edges of the last instruction
+}
+
+// CFG is the control flow graph of a ROM, built by following jumps, calls
+// and skips from the start address. Returns end the flow and computed
+// jumps follow the table of jumps at their base address.
+type CFG struct {
+	ROM          []byte
+	Start        uint16
+	Instructions map[uint16]*Instruction
+	Blocks       []*Block // In address order
+	Entries      []uint16 // Start address and subroutines, in address order
+	Data         []uint16 // Addresses loaded into I, in address order
+}
+
+// NewCFG builds the control flow graph of rom, loaded at start.
+func NewCFG(rom []byte, start uint16) *CFG {
+	g := &CFG{ROM: rom, Start: start, Instructions: map[uint16]*Instruction{}}
+	entries := map[uint16]bool{start: true}
+	data := map[uint16]bool{}
+	work := []uint16{start}
+
+	for len(work) > 0 {
+		addr := work[len(work)-1]
+		work = work[:len(work)-1]
+
+		if _, ok := g.Instructions[addr]; ok {
+			continue
+		}
+
+		in := g.decode(addr)
+		g.Instructions[addr] = in
+
+		if !in.Valid {
+			continue
+		}
+
+		switch op := in.Opcode; {
+		case op&0xF000 == 0xA000:
+			data[op&0x0FFF] = true
+		case op == 0xF000:
+			data[in.Long] = true
+		case op&0xF000 == 0x2000:
+			entries[op&0x0FFF] = true
+		}
+
+		for _, e := range in.Edges {
+			work = append(work, e.To)
+		}
+	}
+
+	g.Entries = sortedKeys(entries)
+	g.Data = sortedKeys(data)
+	g.blocks()
+
+	return g
+}
+
+// word returns the big endian word at addr, ok is false past the ROM.
+func (g *CFG) word(addr uint16) (uint16, bool) {
+	i := int(addr) - int(g.Start)
+
+	if i < 0 || i+1 >= len(g.ROM) {
+		return 0, false
+	}
+
+	return uint16(g.ROM[i])<<8 | uint16(g.ROM[i+1]), true
+}
+
+// size returns the size of the instruction at addr.
+func (g *CFG) size(addr uint16) uint16 {
+	if op, _ := g.word(addr); op == 0xF000 {
+		return 4
+	}
+
+	return 2
+}
+
+func (g *CFG) decode(addr uint16) *Instruction {
+	in := &Instruction{Addr: addr, Size: 2}
+	op, ok := g.word(addr)
+
+	if !ok {
+		in.Outside = true
+		return in
+	}
+
+	in.Opcode = op
+
+	if op == 0xF000 {
+		in.Size = 4
+
+		if in.Long, ok = g.word(addr + 2); !ok {
+			in.Outside = true
+			return in
+		}
+	}
+
+	in.Valid = Valid(op)
+
+	if !in.Valid {
+		return in
+	}
+
+	next := addr + in.Size
+	nnn := op & 0x0FFF
+
+	switch {
+	case op == 0x00EE, op == 0x00FD:
+	case op&0xF000 == 0x1000, disasm.Mnemonic(op) == "0NNN JP addr":
+		in.Edges = []Edge{{To: nnn, Kind: EDGE_JUMP}}
+	case op&0xF000 == 0x2000:
+		in.Edges = []Edge{{To: nnn, Kind: EDGE_CALL}, {To: next, Kind: EDGE_NEXT}}
+	case op&0xF000 == 0xB000:
+		in.Edges = g.table(nnn)
+	case Skips(op):
+		in.Edges = []Edge{{To: next, Kind: EDGE_NEXT}, {To: next + g.size(next), Kind: EDGE_SKIP}}
+	default:
+		in.Edges = []Edge{{To: next, Kind: EDGE_NEXT}}
+	}
+
+	return in
+}
+
+// table returns the edges of a computed jump to base, the jumps that
+// follow base or base itself.
+func (g *CFG) table(base uint16) []Edge {
+	edges := []Edge{{To: base, Kind: EDGE_TABLE}}
+
+	for i := uint16(1); i < JUMP_TABLE_SIZE; i++ {
+		op, ok := g.word(base + 2*i)
+
+		if !ok || op&0xF000 != 0x1000 {
+			break
+		}
+
+		edges = append(edges, Edge{To: base + 2*i, Kind: EDGE_TABLE})
+	}
+
+	return edges
+}
+
+// blocks splits the valid instructions into basic blocks.
+func (g *CFG) blocks() {
+	leaders := map[uint16]bool{g.Start: true}
+
+	for _, in := range g.Instructions {
+		for _, e := range in.Edges {
+			if e.Kind != EDGE_NEXT || len(in.Edges) > 1 {
+				leaders[e.To] = true
+			}
+		}
+	}
+
+	for _, addr := range sortedKeys(g.Instructions) {
+		in := g.Instructions[addr]
+
+		if !in.Valid {
+			continue
+		}
+
+		var b *Block
+
+		if n := len(g.Blocks); n > 0 && !leaders[addr] && g.Blocks[n-1].End == addr && len(g.Blocks[n-1].Edges) == 1 && g.Blocks[n-1].Edges[0].Kind == EDGE_NEXT {
+			b = g.Blocks[n-1]
+		} else {
+			b = &Block{Start: addr}
+			g.Blocks = append(g.Blocks, b)
+		}
+
+		b.Instructions = append(b.Instructions, in)
+		b.End = addr + in.Size
+		b.Edges = in.Edges
+	}
+}
+
+// Code reports whether addr is a byte of a valid instruction reached.
+func (g *CFG) Code(addr uint16) bool {
+	for _, a := range []uint16{addr, addr - 1, addr - 2, addr - 3} {
+		if in, ok := g.Instructions[a]; ok && in.Valid && addr < a+in.Size {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Valid reports whether op is a CHIP-8, SUPER-CHIP or XO-CHIP instruction.
+// 0000 is padding rather than a machine code call.
+func Valid(op uint16) bool {
+	return op != 0x0000 && (disasm.Mnemonic(op) != "" || Requirement(op) == "XO-CHIP")
+}
+
+// Skips reports whether op conditionally skips the following instruction.
+func Skips(op uint16) bool {
+	switch op & 0xF000 {
+	case 0x3000, 0x4000:
+		return true
+	case 0x5000, 0x9000:
+		return op&0xF == 0x0
+	case 0xE000:
+		return op&0xFF == 0x9E || op&0xFF == 0xA1
+	}
+
+	return false
+}
+
+// Requirement returns the extension an instruction needs, SCHIP, XO-CHIP
+// or MEGA-CHIP, or an empty string for CHIP-8 instructions.
+func Requirement(op uint16) string {
+	switch {
+	case op == 0x0010 || op == 0x0011:
+		return "MEGA-CHIP"
+	case op&0xFFF0 == 0x00C0, op >= 0x00FB && op <= 0x00FF, op&0xF00F == 0xD000,
+		op&0xF0FF == 0xF030, op&0xF0FF == 0xF075, op&0xF0FF == 0xF085:
+		return "SCHIP"
+	case op&0xFFF0 == 0x00D0, op&0xF00F == 0x5002, op&0xF00F == 0x5003, op == 0xF000,
+		op&0xF0FF == 0xF001, op == 0xF002, op&0xF0FF == 0xF03A:
+		return "XO-CHIP"
+	}
+
+	return ""
+}
+
+func sortedKeys[V any](m map[uint16]V) []uint16 {
+	keys := make([]uint16, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	return keys
+}
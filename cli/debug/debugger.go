@@ -2,6 +2,8 @@ package debug
 
 import (
 	"fmt"
+
+	"github.com/gaoliveira21/chip8/core/disasm"
 )
 
 type DisassemblerResponse struct {
@@ -9,8 +11,14 @@ type DisassemblerResponse struct {
 	RomName      string   `json:"romName"`
 }
 
-func NewDebugger(rom []byte, romName string) {
-	instructions := Disassemble(rom)
+// NewDebugger prints the disassembly of rom, as a listing with addresses
+// and labels when there are symbols.
+func NewDebugger(rom []byte, romName string, start uint16, symbols *disasm.Symbols) {
+	instructions := disasm.Disassemble(rom)
+
+	if symbols != nil {
+		instructions = disasm.Listing(rom, start, symbols)
+	}
 
 	for _, v := range instructions {
 		fmt.Print(v)
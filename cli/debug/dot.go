@@ -0,0 +1,137 @@
+package debug
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gaoliveira21/chip8/core/disasm"
+)
+
+// Subroutines assigns every block to the subroutine it belongs to, the
+// entry it is reached from without calls. Blocks shared by several
+// subroutines go to the first one by address.
+func (g *CFG) Subroutines() map[uint16]uint16 {
+	blocks := map[uint16]*Block{}
+
+	for _, b := range g.Blocks {
+		blocks[b.Start] = b
+	}
+
+	owner := map[uint16]uint16{}
+
+	for _, entry := range g.Entries {
+		work := []uint16{entry}
+
+		for len(work) > 0 {
+			addr := work[len(work)-1]
+			work = work[:len(work)-1]
+			b, ok := blocks[addr]
+
+			if _, owned := owner[addr]; owned || !ok {
+				continue
+			}
+
+			owner[addr] = entry
+
+			for _, e := range b.Edges {
+				if e.Kind != EDGE_CALL {
+					work = append(work, e.To)
+				}
+			}
+		}
+	}
+
+	return owner
+}
+
+// WriteDOT writes the graph in the DOT language of Graphviz, with a
+// cluster per subroutine and a node per basic block listing its
+// instructions. Jumps are bold, calls dashed, skips labelled and computed
+// jumps red and labelled indirect.
+func (g *CFG) WriteDOT(w io.Writer, symbols *disasm.Symbols) error {
+	out := bufio.NewWriter(w)
+	owner := g.Subroutines()
+
+	fmt.Fprintln(out, "digraph cfg {")
+	fmt.Fprintln(out, "\tnode [shape=box fontname=monospace];")
+
+	for _, entry := range g.Entries {
+		name := symbols.Name(entry)
+
+		switch {
+		case name != "":
+		case entry == g.Start:
+			name = "main"
+		default:
+			name = fmt.Sprintf("sub_%04X", entry)
+		}
+
+		fmt.Fprintf(out, "\tsubgraph cluster_%04X {\n\t\tlabel=%q;\n", entry, name)
+
+		for _, b := range g.Blocks {
+			if owner[b.Start] == entry {
+				fmt.Fprintf(out, "\t\t%s [label=\"%s\"];\n", node(b.Start), blockLabel(b, symbols))
+			}
+		}
+
+		fmt.Fprintln(out, "\t}")
+	}
+
+	for _, addr := range sortedKeys(g.Instructions) {
+		if in := g.Instructions[addr]; !in.Valid {
+			fmt.Fprintf(out, "\t%s [label=\"%04X data\" style=dashed];\n", node(addr), addr)
+		}
+	}
+
+	for _, b := range g.Blocks {
+		for _, e := range b.Edges {
+			fmt.Fprintf(out, "\t%s -> %s%s;\n", node(b.Start), node(e.To), edgeAttributes(e.Kind))
+		}
+	}
+
+	fmt.Fprintln(out, "}")
+
+	return out.Flush()
+}
+
+func node(addr uint16) string {
+	return fmt.Sprintf("b%04X", addr)
+}
+
+// blockLabel lists the instructions of a block, left aligned.
+func blockLabel(b *Block, symbols *disasm.Symbols) string {
+	var s strings.Builder
+
+	if label, ok := symbols.Label(b.Start); ok {
+		s.WriteString(label + ":\\l")
+	}
+
+	for _, in := range b.Instructions {
+		line := strings.TrimSpace(fmt.Sprintf("%04X  %04X  %s%s", in.Addr, in.Opcode, disasm.Mnemonic(in.Opcode), symbols.Comment(in.Opcode)))
+
+		if in.Opcode == 0xF000 {
+			line = fmt.Sprintf("%04X  F000 %04X  LD I, long", in.Addr, in.Long)
+		}
+
+		s.WriteString(strings.ReplaceAll(line, `"`, `\"`) + "\\l")
+	}
+
+	return s.String()
+}
+
+func edgeAttributes(kind int) string {
+	switch kind {
+	case EDGE_JUMP:
+		return " [style=bold]"
+	case EDGE_CALL:
+		return " [style=dashed label=\"call\"]"
+	case EDGE_SKIP:
+		return " [label=\"skip\"]"
+	case EDGE_TABLE:
+		return " [color=red label=\"indirect\"]"
+	}
+
+	return ""
+}
@@ -0,0 +1,45 @@
+package debug_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gaoliveira21/chip8/cli/debug"
+	"github.com/gaoliveira21/chip8/core/disasm"
+)
+
+func TestWriteDOT(t *testing.T) {
+	rom := []byte{
+		0x22, 0x06, // 200: CALL draw
+		0xB2, 0x0A, // 202: JP V0, table
+		0x12, 0x00, // 204: JP 0x200
+		0x30, 0x01, // 206: draw: SE V0, 1
+		0x00, 0xEE, // 208: RET
+		0x12, 0x00, // 20A: table: JP 0x200
+		0x12, 0x04, // 20C: JP 0x204
+	}
+
+	symbols := disasm.NewSymbols()
+	symbols.AddLabel("draw", 0x206)
+
+	var out strings.Builder
+
+	if err := debug.NewCFG(rom, 0x200).WriteDOT(&out, symbols); err != nil {
+		t.Fatal(err)
+	}
+
+	dot := out.String()
+
+	for _, s := range []string{
+		"subgraph cluster_0200 {\n\t\tlabel=\"main\";",
+		"subgraph cluster_0206 {\n\t\tlabel=\"draw\";\n\t\tb0206 [label=\"draw:\\l0206  3001  3XKK SE Vx, byte\\l\"];",
+		"b0200 -> b0206 [style=dashed label=\"call\"];",
+		"b0202 -> b020C [color=red label=\"indirect\"];",
+		"b0206 -> b020A [label=\"skip\"];",
+		"b020C -> b0204 [style=bold];",
+	} {
+		if !strings.Contains(dot, s) {
+			t.Errorf("WriteDOT() = %s; expected %q", dot, s)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package debug_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gaoliveira21/chip8/cli/debug"
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/disasm"
+)
+
+func addROMs(f *testing.F) {
+	roms, err := filepath.Glob("../roms/*.ch8")
+
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, path := range roms {
+		rom, err := os.ReadFile(path)
+
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(rom)
+	}
+}
+
+// FuzzDisassemble analyzes arbitrary ROMs, none of the tools may panic.
+//
+//	go test ./cli/debug -fuzz FuzzDisassemble
+func FuzzDisassemble(f *testing.F) {
+	addROMs(f)
+	f.Add([]byte{0x12})             // Odd length
+	f.Add([]byte{0xB2, 0x00, 0x12}) // Jump table past the end
+
+	f.Fuzz(func(t *testing.T, rom []byte) {
+		disasm.Disassemble(rom)
+		disasm.Listing(rom, 0x200, nil)
+
+		g := debug.NewCFG(rom, 0x200)
+		debug.Lint(g)
+
+		if err := g.WriteDOT(io.Discard, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// FuzzDecoder runs single instructions and compares the decoding of the
+// CPU with the disassembler: the CPU runs exactly the instructions that
+// have a mnemonic, on CHIP-8 those without an extension requirement, and
+// jumps to one of the successors of the instruction in the control flow
+// graph.
+//
+//	go test ./cli/debug -fuzz FuzzDecoder
+func FuzzDecoder(f *testing.F) {
+	for _, op := range []uint16{0x00E0, 0x00EE, 0x00FD, 0x00C4, 0x1234, 0x2345, 0x3102, 0x5120, 0x5121, 0x8126, 0x812F, 0x9120, 0x9121, 0xB300, 0xD125, 0xD120, 0xE19E, 0xE1A1, 0xE1A2, 0xF10A, 0xF175, 0xF1FF} {
+		f.Add(op, []byte{0x01, 0x01, 0x02})
+	}
+
+	f.Fuzz(func(t *testing.T, op uint16, registers []byte) {
+		rom := []byte{byte(op >> 8), byte(op)}
+		mnemonic := disasm.Mnemonic(op)
+
+		for _, platform := range []cpu.Platform{cpu.PLATFORM_CHIP8, cpu.PLATFORM_SCHIP} {
+			c := cpu.NewCpuForPlatform(platform)
+			c.Seed(0)
+			c.LoadROM(rom)
+
+			for x, v := range registers[:min(len(registers), 16)] {
+				c.SetV(uint8(x), v)
+			}
+
+			err := c.Run()
+
+			var unsupported *cpu.UnsupportedOpcodeError
+			supported := !errors.As(err, &unsupported)
+			// DXY0 draws nothing on CHIP-8, 0010 and 0011 are 0NNN jumps
+			// outside of MEGA-CHIP
+			r := debug.Requirement(op)
+			expected := mnemonic != "" && (platform.SCHIP() || r == "" || r == "MEGA-CHIP" || op&0xF00F == 0xD000)
+
+			if supported != expected {
+				t.Fatalf("%s: %04X runs = %t (%v); expected %t for mnemonic %q", platform, op, supported, err, expected, mnemonic)
+			}
+
+			in := debug.NewCFG(rom, 0x200).Instructions[0x200]
+
+			// 0000 is padding rather than code to the graph
+			if err != nil || !in.Valid {
+				continue
+			}
+
+			targets := []uint16{}
+
+			for _, e := range in.Edges {
+				if e.Kind == debug.EDGE_TABLE {
+					targets = nil
+					break
+				}
+
+				targets = append(targets, e.To)
+			}
+
+			if op&0xF0FF == 0xF00A {
+				targets = append(targets, 0x200) // Waits for a key
+			}
+
+			if targets != nil && !slices.Contains(targets, c.PC()) {
+				t.Errorf("%s: %04X jumps to %04X; expected one of %04X", platform, op, c.PC(), targets)
+			}
+		}
+	})
+}
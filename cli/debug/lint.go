@@ -0,0 +1,224 @@
+package debug
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Kinds of lint issues.
+const (
+	LINT_UNREACHABLE    = "unreachable"
+	LINT_JUMP           = "jump"
+	LINT_SELF_MODIFYING = "self-modifying"
+	LINT_PLATFORM       = "platform"
+	LINT_QUIRK          = "quirk"
+)
+
+// Issue is a finding of Lint.
+type Issue struct {
+	Addr    uint16
+	Kind    string
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%04X: %s: %s", i.Addr, i.Kind, i.Message)
+}
+
+// Lint analyzes the control flow graph of a ROM and reports unreachable
+// code, jumps into data, writes into code, instructions of extensions and
+// instructions that behave differently depending on quirks, in address
+// order.
+func Lint(g *CFG) []Issue {
+	issues := []Issue{}
+	issues = append(issues, lintJumps(g)...)
+	issues = append(issues, lintUnreachable(g)...)
+
+	for _, b := range g.Blocks {
+		issues = append(issues, lintBlock(g, b)...)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Addr < issues[j].Addr })
+
+	return issues
+}
+
+// lintJumps reports the edges to odd addresses, outside of the ROM and to
+// data.
+func lintJumps(g *CFG) []Issue {
+	issues := []Issue{}
+	names := map[int]string{EDGE_NEXT: "runs into", EDGE_JUMP: "jumps to", EDGE_CALL: "calls", EDGE_SKIP: "skips to", EDGE_TABLE: "jumps through a table to"}
+
+	for _, addr := range sortedKeys(g.Instructions) {
+		in := g.Instructions[addr]
+
+		for _, e := range in.Edges {
+			target := g.Instructions[e.To]
+
+			switch {
+			case target.Outside:
+				issues = append(issues, Issue{addr, LINT_JUMP, fmt.Sprintf("%s %04X, outside of the ROM", names[e.Kind], e.To)})
+			case !target.Valid:
+				issues = append(issues, Issue{addr, LINT_JUMP, fmt.Sprintf("%s data at %04X (%04X is not an instruction)", names[e.Kind], e.To, target.Opcode)})
+			case e.To%2 != 0 && e.Kind != EDGE_NEXT && e.Kind != EDGE_SKIP:
+				issues = append(issues, Issue{addr, LINT_JUMP, fmt.Sprintf("%s odd address %04X", names[e.Kind], e.To)})
+			}
+		}
+	}
+
+	return issues
+}
+
+// lintUnreachable reports the ranges of the ROM that are not reached and
+// look like code: instructions only, ending with a jump or a return and
+// without any address loaded into I, which would make them data.
+func lintUnreachable(g *CFG) []Issue {
+	issues := []Issue{}
+	end := g.Start + uint16(len(g.ROM))
+
+	for addr := g.Start; addr < end; {
+		if g.Code(addr) {
+			addr++
+			continue
+		}
+
+		start := addr
+
+		for addr < end && !g.Code(addr) {
+			addr++
+		}
+
+		if looksLikeCode(g, start, addr) {
+			issues = append(issues, Issue{start, LINT_UNREACHABLE, fmt.Sprintf("%04X-%04X is never reached", start, addr-1)})
+		}
+	}
+
+	return issues
+}
+
+func looksLikeCode(g *CFG, start uint16, end uint16) bool {
+	for _, d := range g.Data {
+		if d >= start && d < end {
+			return false
+		}
+	}
+
+	count := 0
+	ends := false
+
+	for addr := start; addr+1 < end; addr += 2 {
+		op, _ := g.word(addr)
+
+		if !Valid(op) {
+			return false
+		}
+
+		count++
+		ends = op == 0x00EE || op&0xF000 == 0x1000
+	}
+
+	return count >= 2 && ends
+}
+
+// lintBlock reports writes into code, instructions of extensions and
+// quirk dependencies. I is followed from the ANNN of the block.
+func lintBlock(g *CFG, b *Block) []Issue {
+	issues := []Issue{}
+	i, known := uint16(0), false
+
+	for n, in := range b.Instructions {
+		op := in.Opcode
+		x, y := op>>8&0xF, op>>4&0xF
+
+		if r := Requirement(op); r != "" {
+			issues = append(issues, Issue{in.Addr, LINT_PLATFORM, fmt.Sprintf("%04X needs %s", op, r)})
+		} else if op&0xF000 == 0x0000 && op != 0x00E0 && op != 0x00EE {
+			issues = append(issues, Issue{in.Addr, LINT_PLATFORM, fmt.Sprintf("%04X calls a machine code routine, only the COSMAC VIP runs it", op)})
+		}
+
+		var next *Instruction
+
+		if n+1 < len(b.Instructions) {
+			next = b.Instructions[n+1]
+		}
+
+		switch {
+		case op&0xF00F == 0x8006 || op&0xF00F == 0x800E:
+			if x != y {
+				issues = append(issues, Issue{in.Addr, LINT_QUIRK, fmt.Sprintf("%04X shifts VY into VX on CHIP-8 but VX in place on SUPER-CHIP", op)})
+			}
+		case op&0xF000 == 0xB000 && x != 0:
+			issues = append(issues, Issue{in.Addr, LINT_QUIRK, fmt.Sprintf("%04X adds V0 on CHIP-8 but V%X on SUPER-CHIP", op, x)})
+		case op&0xF00F >= 0x8001 && op&0xF00F <= 0x8003 && next != nil && readsVF(next.Opcode):
+			issues = append(issues, Issue{in.Addr, LINT_QUIRK, fmt.Sprintf("%04X resets VF on CHIP-8 only, read by %04X", op, next.Opcode)})
+		case (op&0xF0FF == 0xF055 || op&0xF0FF == 0xF065) && next != nil && usesI(next.Opcode):
+			issues = append(issues, Issue{in.Addr, LINT_QUIRK, fmt.Sprintf("%04X moves I past the registers on CHIP-8 only, used by %04X", op, next.Opcode)})
+		}
+
+		var written uint16
+
+		switch {
+		case op&0xF000 == 0xA000:
+			i, known = op&0x0FFF, true
+		case op == 0xF000:
+			i, known = in.Long, true
+		case op&0xF0FF == 0xF033:
+			written = 3
+		case op&0xF0FF == 0xF055:
+			written = x + 1
+		case op&0xF00F == 0x5002:
+			written = max(x, y) - min(x, y) + 1
+		}
+
+		if known && written > 0 {
+			for addr := i; addr < i+written; addr++ {
+				if g.Code(addr) {
+					issues = append(issues, Issue{in.Addr, LINT_SELF_MODIFYING, fmt.Sprintf("%04X writes into the code at %04X", op, addr)})
+					break
+				}
+			}
+		}
+
+		if op&0xF0FF == 0xF01E || op&0xF0FF == 0xF029 || op&0xF0FF == 0xF030 || op&0xF0FF == 0xF055 || op&0xF0FF == 0xF065 {
+			known = false
+		}
+	}
+
+	return issues
+}
+
+// readsVF reports whether op reads VF before writing it.
+func readsVF(op uint16) bool {
+	x, y := op>>8&0xF, op>>4&0xF
+
+	switch op & 0xF000 {
+	case 0x3000, 0x4000, 0x7000, 0xE000:
+		return x == 0xF
+	case 0x5000, 0x9000:
+		return x == 0xF || y == 0xF
+	case 0x8000:
+		return y == 0xF || (x == 0xF && op&0xF != 0x0)
+	case 0xF000:
+		switch op & 0xFF {
+		case 0x15, 0x18, 0x1E, 0x29, 0x30, 0x33, 0x55:
+			return x == 0xF
+		}
+	}
+
+	return false
+}
+
+// usesI reports whether op reads the address in I.
+func usesI(op uint16) bool {
+	switch {
+	case op&0xF000 == 0xD000:
+		return true
+	case op&0xF000 == 0xF000:
+		switch op & 0xFF {
+		case 0x1E, 0x33, 0x55, 0x65:
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package debug_test
+
+import (
+	"testing"
+
+	"github.com/gaoliveira21/chip8/cli/debug"
+)
+
+func TestCFG(t *testing.T) {
+	// CALL sub; SE V0, 1; JP 0x200; 00FD; sub: RET
+	g := debug.NewCFG([]byte{0x22, 0x0A, 0x30, 0x01, 0x12, 0x00, 0x00, 0xFD, 0x00, 0x00, 0x00, 0xEE}, 0x200)
+
+	starts := []uint16{}
+
+	for _, b := range g.Blocks {
+		starts = append(starts, b.Start)
+	}
+
+	expected := []uint16{0x200, 0x202, 0x204, 0x206, 0x20A}
+
+	if len(starts) != len(expected) {
+		t.Fatalf("blocks at %X; expected %X", starts, expected)
+	}
+
+	for i := range expected {
+		if starts[i] != expected[i] {
+			t.Errorf("blocks at %X; expected %X", starts, expected)
+			break
+		}
+	}
+
+	if len(g.Entries) != 2 || g.Entries[1] != 0x20A {
+		t.Errorf("Entries = %X; expected [200 20A]", g.Entries)
+	}
+
+	if g.Code(0x208) || !g.Code(0x20B) {
+		t.Errorf("Code(0x208) = %t, Code(0x20B) = %t; expected padding and code", g.Code(0x208), g.Code(0x20B))
+	}
+}
+
+func TestLint(t *testing.T) {
+	rom := []byte{
+		0xA2, 0x02, // 200: LD I, 0x202
+		0xF0, 0x55, // 202: LD [I], V0, into its own code
+		0x81, 0x26, // 204: SHR V1, V2
+		0x00, 0xFF, // 206: HIRES
+		0x80, 0x11, // 208: OR V0, V1
+		0x3F, 0x00, // 20A: SE VF, 0
+		0x12, 0x16, // 20C: JP 0x216, into data
+		0x12, 0x14, // 20E: JP 0x214, skipped to
+		0x12, 0x00, // 210: unreachable
+		0x00, 0xEE, // 212
+		0x00, 0xEE, // 214
+		0xFF, 0xFF, // 216: not an instruction
+	}
+
+	issues := debug.Lint(debug.NewCFG(rom, 0x200))
+	expected := []debug.Issue{
+		{Addr: 0x202, Kind: debug.LINT_SELF_MODIFYING},
+		{Addr: 0x204, Kind: debug.LINT_QUIRK},
+		{Addr: 0x206, Kind: debug.LINT_PLATFORM},
+		{Addr: 0x208, Kind: debug.LINT_QUIRK},
+		{Addr: 0x20C, Kind: debug.LINT_JUMP},
+		{Addr: 0x210, Kind: debug.LINT_UNREACHABLE},
+	}
+
+	if len(issues) != len(expected) {
+		t.Fatalf("Lint() = %v; expected %d issues", issues, len(expected))
+	}
+
+	for i := range expected {
+		if issues[i].Addr != expected[i].Addr || issues[i].Kind != expected[i].Kind {
+			t.Errorf("Lint()[%d] = %v; expected %s at %04X", i, issues[i], expected[i].Kind, expected[i].Addr)
+		}
+	}
+}
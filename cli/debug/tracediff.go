@@ -0,0 +1,128 @@
+package debug
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/disasm"
+)
+
+// Divergence is the first entry where two traces differ.
+type Divergence struct {
+	Index  int             // Entry number, from 0
+	A      *cpu.TraceEntry // nil when the first trace ended before
+	B      *cpu.TraceEntry // nil when the second trace ended before
+	Fields []string        // Fields that differ
+}
+
+// DiffTraces compares two traces entry by entry and returns the first
+// divergence, or nil when they are identical.
+func DiffTraces(a *cpu.TraceReader, b *cpu.TraceReader) (*Divergence, error) {
+	for index := 0; ; index++ {
+		ea, errA := next(a)
+		eb, errB := next(b)
+
+		if errA != nil {
+			return nil, fmt.Errorf("first trace: %w", errA)
+		}
+
+		if errB != nil {
+			return nil, fmt.Errorf("second trace: %w", errB)
+		}
+
+		if ea == nil && eb == nil {
+			return nil, nil
+		}
+
+		d := &Divergence{Index: index, A: ea, B: eb}
+
+		if ea == nil || eb == nil {
+			return d, nil
+		}
+
+		if d.Fields = DiffEntries(*ea, *eb); len(d.Fields) > 0 {
+			return d, nil
+		}
+	}
+}
+
+func next(r *cpu.TraceReader) (*cpu.TraceEntry, error) {
+	e, err := r.Next()
+
+	if err == io.EOF {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// DiffEntries returns the names of the fields that differ, as in the text
+// trace format.
+func DiffEntries(a cpu.TraceEntry, b cpu.TraceEntry) []string {
+	fields := []string{}
+
+	if a.Cycle != b.Cycle {
+		fields = append(fields, "cycle")
+	}
+
+	if a.PC != b.PC {
+		fields = append(fields, "pc")
+	}
+
+	if a.Opcode != b.Opcode {
+		fields = append(fields, "op")
+	}
+
+	if a.I != b.I {
+		fields = append(fields, "i")
+	}
+
+	for x := range a.V {
+		if a.V[x] != b.V[x] {
+			fields = append(fields, fmt.Sprintf("v%X", x))
+		}
+	}
+
+	if a.DelayTimer != b.DelayTimer {
+		fields = append(fields, "dt")
+	}
+
+	if a.SoundTimer != b.SoundTimer {
+		fields = append(fields, "st")
+	}
+
+	if a.SP != b.SP {
+		fields = append(fields, "sp")
+	}
+
+	return fields
+}
+
+func (d *Divergence) String() string {
+	var b strings.Builder
+
+	switch {
+	case d.A == nil:
+		fmt.Fprintf(&b, "entry %d: first trace ended\n", d.Index)
+	case d.B == nil:
+		fmt.Fprintf(&b, "entry %d: second trace ended\n", d.Index)
+	default:
+		fmt.Fprintf(&b, "entry %d: %s differ\n", d.Index, strings.Join(d.Fields, ", "))
+	}
+
+	if d.A != nil {
+		fmt.Fprintf(&b, "< %s\n", d.A.Format(disasm.Mnemonic))
+	}
+
+	if d.B != nil {
+		fmt.Fprintf(&b, "> %s\n", d.B.Format(disasm.Mnemonic))
+	}
+
+	return b.String()
+}
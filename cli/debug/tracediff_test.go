@@ -0,0 +1,49 @@
+package debug_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gaoliveira21/chip8/cli/debug"
+	"github.com/gaoliveira21/chip8/core/cpu"
+)
+
+const trace = `0 pc=0200 op=6012 i=0000 v=00,00,00,00,00,00,00,00,00,00,00,00,00,00,00,00 dt=00 st=00 sp=0 ; 6XKK LD Vx, byte
+1 pc=0202 op=7001 i=0000 v=12,00,00,00,00,00,00,00,00,00,00,00,00,00,00,00 dt=00 st=00 sp=0
+`
+
+func reader(t *testing.T, s string) *cpu.TraceReader {
+	r, err := cpu.NewTraceReader(strings.NewReader(s))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+func TestDiffTraces(t *testing.T) {
+	d, err := debug.DiffTraces(reader(t, trace), reader(t, trace))
+
+	if err != nil || d != nil {
+		t.Fatalf("DiffTraces() = %v, %v; expected nil, nil", d, err)
+	}
+
+	other := strings.Replace(trace, "v=12,00", "v=13,00", 1)
+	d, err = debug.DiffTraces(reader(t, trace), reader(t, other))
+
+	if err != nil || d == nil {
+		t.Fatalf("DiffTraces() = %v, %v; expected a divergence", d, err)
+	}
+
+	if d.Index != 1 || strings.Join(d.Fields, ",") != "v0" {
+		t.Errorf("divergence = %d %v; expected 1 [v0]", d.Index, d.Fields)
+	}
+
+	short := strings.SplitAfter(trace, "\n")[0]
+	d, _ = debug.DiffTraces(reader(t, trace), reader(t, short))
+
+	if d == nil || d.Index != 1 || d.B != nil {
+		t.Errorf("expected the second trace to end at entry 1, got %v", d)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gaoliveira21/chip8/cli/debug"
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/disasm"
+)
+
+// analyzed is a ROM loaded by the commands that analyze ROM files.
+type analyzed struct {
+	path    string
+	rom     []byte
+	start   uint16
+	symbols *disasm.Symbols
+}
+
+// analyze parses the arguments of a command that analyzes a ROM file:
+// -platform, -symbols and the path of the ROM.
+func analyze(name string, description string, args []string) (*analyzed, error) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	platform := flags.String("platform", string(cpu.PLATFORM_SCHIP), "Platform, for the start address")
+	symbolPaths := []string{}
+	flags.Func("symbols", "Load labels from a symbol file to name addresses (repeatable)", func(path string) error {
+		symbolPaths = append(symbolPaths, path)
+		return nil
+	})
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: chip8 %s [flags] rom.ch8\n", name)
+		fmt.Fprintln(flags.Output(), description)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return nil, errors.New("expected a ROM")
+	}
+
+	p, err := cpu.ParsePlatform(*platform)
+
+	if err != nil {
+		return nil, err
+	}
+
+	a := &analyzed{path: flags.Arg(0), start: p.StartAddress()}
+
+	if a.rom, err = os.ReadFile(a.path); err != nil {
+		return nil, err
+	}
+
+	if len(symbolPaths) > 0 {
+		if a.symbols, err = disasm.LoadSymbols(symbolPaths...); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// lint runs the lint command, it prints the issues found in a ROM and
+// returns the exit status: 0 without issues, 1 with issues and 2 on
+// errors.
+func lint(args []string) int {
+	a, err := analyze("lint", "Reports unreachable code, jumps into data, writes into code, extension instructions and quirk dependencies", args)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	issues := debug.Lint(debug.NewCFG(a.rom, a.start))
+
+	for _, i := range issues {
+		if name := a.symbols.Name(i.Addr); name != "" {
+			fmt.Printf("%s: %s (%s)\n", a.path, i, name)
+		} else {
+			fmt.Printf("%s: %s\n", a.path, i)
+		}
+	}
+
+	if len(issues) > 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gaoliveira21/chip8/cli/debug"
+	"github.com/gaoliveira21/chip8/core/cpu"
+)
+
+// traceDiff runs the trace-diff command, it prints the first divergence
+// between two traces and returns the exit status: 0 when the traces are
+// identical, 1 when they differ and 2 on errors.
+func traceDiff(args []string) int {
+	flags := flag.NewFlagSet("trace-diff", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: chip8 trace-diff first second")
+		fmt.Fprintln(flags.Output(), "Reports the first divergence between two text or binary traces")
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	var readers [2]*cpu.TraceReader
+
+	for i, path := range flags.Args() {
+		f, err := os.Open(path)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		defer f.Close()
+
+		if readers[i], err = cpu.NewTraceReader(f); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			return 2
+		}
+	}
+
+	d, err := debug.DiffTraces(readers[0], readers[1])
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if d == nil {
+		fmt.Println("traces are identical")
+		return 0
+	}
+
+	fmt.Print(d)
+
+	return 1
+}
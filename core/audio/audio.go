@@ -1,6 +1,7 @@
 package audio
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"os"
@@ -13,6 +14,10 @@ import (
 
 const sampleRate = 48000
 
+// MAX_SAMPLE_SECONDS bounds the length of digitised sound samples, whose
+// rate and length are read from ROM memory.
+const MAX_SAMPLE_SECONDS = 60
+
 type AudioPlayer = *audio.Player
 
 func readFromFS() (io.Reader, error) {
@@ -28,6 +33,15 @@ func readFromFS() (io.Reader, error) {
 	return r, nil
 }
 
+// context returns the audio context, ebiten allows a single one per process.
+func context() *audio.Context {
+	if c := audio.CurrentContext(); c != nil {
+		return c
+	}
+
+	return audio.NewContext(sampleRate)
+}
+
 func NewAudioPlayer() (AudioPlayer, error) {
 	execMode := os.Getenv("EXEC_MODE")
 
@@ -50,8 +64,7 @@ func NewAudioPlayer() (AudioPlayer, error) {
 		return nil, err
 	}
 
-	audioContext := audio.NewContext(sampleRate)
-	p, err := audioContext.NewPlayer(stream)
+	p, err := context().NewPlayer(stream)
 
 	if err != nil {
 		return nil, err
@@ -59,3 +72,34 @@ func NewAudioPlayer() (AudioPlayer, error) {
 
 	return p, nil
 }
+
+// NewSamplePlayer plays unsigned 8 bit mono samples recorded at rate, used
+// by the MEGA-CHIP digitised sound channel.
+func NewSamplePlayer(rate int, data []byte, loop bool) (AudioPlayer, error) {
+	if os.Getenv("EXEC_MODE") == "web" {
+		return nil, errors.New("audio unsuported on web")
+	}
+
+	if rate <= 0 || len(data) == 0 {
+		return nil, errors.New("empty sample")
+	}
+
+	frames := min(len(data)*sampleRate/rate, MAX_SAMPLE_SECONDS*sampleRate)
+	pcm := make([]byte, frames*4)
+
+	for i := 0; i < frames; i++ {
+		s := (int16(data[i*rate/sampleRate]) - 0x80) << 8
+
+		// 16 bit little endian, same value on both channels
+		pcm[i*4] = byte(s)
+		pcm[i*4+1] = byte(s >> 8)
+		pcm[i*4+2] = byte(s)
+		pcm[i*4+3] = byte(s >> 8)
+	}
+
+	if loop {
+		return context().NewPlayer(audio.NewInfiniteLoop(bytes.NewReader(pcm), int64(len(pcm))))
+	}
+
+	return context().NewPlayer(bytes.NewReader(pcm))
+}
@@ -1,36 +1,157 @@
 package core
 
 import (
+	"errors"
+	"image"
 	"image/color"
 	"log"
+	"math"
 
 	"github.com/gaoliveira21/chip8/core/audio"
+	"github.com/gaoliveira21/chip8/core/config"
 	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/disasm"
 	"github.com/gaoliveira21/chip8/core/input"
+	"github.com/gaoliveira21/chip8/core/render"
+	"github.com/gaoliveira21/chip8/core/ui"
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 type Chip8 struct {
 	cpu         *cpu.CPU
-	square      *ebiten.Image
+	renderer    *render.Renderer
+	display     *ebiten.Image
+	canvas      *ebiten.Image
+	filter      *ebiten.Shader
 	audioPlayer audio.AudioPlayer
+	cfg         *config.Config
+	rom         []byte // nil until a ROM is chosen
+	title       string
+	speed       int // Instructions per frame
+	quit        bool
+	debug       bool // Show the debug overlay
+	memory      *ui.MemoryViewer
+	ips         ipsCounter
+	tools       DebugOptions // Set on every machine
+	stop        error        // Breakpoint or error the machine is stopped at
+
+	// Menu
+	menu     *ui.Menu
+	menuOpen bool
+	browser  *ui.Browser
+	states   [SAVE_SLOTS]*cpu.CPU
+	slot     int
+	changed  bool // Settings changed since the menu opened
+
+	// Input
+	keyboard *input.Keyboard
+	gamepad  *input.Gamepad
+	keypad   input.Sources
+	keypad2  input.Sources
+	touch    *ui.TouchKeypad
+	remap    *ui.Remap
+
+	// MEGA-CHIP
+	colorDisplay *ebiten.Image
+	sample       *cpu.Sample
+	samplePlayer audio.AudioPlayer
 }
 
 func (c8 *Chip8) Update() error {
-	for i := 0; i < cpu.SPEED; i++ {
-		for key, value := range input.Keypad {
-			if ebiten.IsKeyPressed(key) {
-				c8.cpu.Keys[value] = 0x01
-			} else {
-				c8.cpu.Keys[value] = 0x00
-			}
+	if c8.quit {
+		return ebiten.Termination
+	}
+
+	// Debuggers are served even while a menu is open
+	debuggerRuns := true
+
+	for _, d := range c8.tools.Debuggers {
+		if !d.Poll() {
+			debuggerRuns = false
 		}
+	}
+
+	if c8.remap != nil {
+		c8.updateRemap()
+		return nil
+	}
+
+	if c8.menuOpen {
+		c8.updateMenu()
+		return nil
+	}
 
-		c8.cpu.Run()
+	if inpututil.IsKeyJustPressed(ui.REMAP_KEY) {
+		c8.remap = ui.NewRemap(c8.keyboard.Bindings, c8.gamepad.Bindings)
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ui.DEBUG_KEY) {
+		c8.debug = !c8.debug
+	}
 
-		if c8.cpu.ResizeWindow {
-			ebiten.SetWindowSize(c8.cpu.Graphics.Width*10, c8.cpu.Graphics.Height*10)
-			c8.cpu.ResizeWindow = false
+	if inpututil.IsKeyJustPressed(ui.MEMORY_KEY) {
+		c8.toggleMemoryViewer()
+	}
+
+	if c8.memory != nil {
+		c8.memory.Update()
+	}
+
+	if inpututil.IsKeyJustPressed(ui.MENU_KEY) || c8.rom == nil {
+		c8.openMenu()
+		return nil
+	}
+
+	c8.keypad.Read(c8.cpu.Keys[:])
+
+	if c8.cpu.Platform == cpu.PLATFORM_CHIP8X {
+		c8.keypad2.Read(c8.cpu.Keys2[:])
+	}
+
+	if !debuggerRuns {
+		return nil
+	}
+
+	steps := c8.speed
+
+	if c8.stop != nil {
+		var b *cpu.BreakError
+
+		switch {
+		case !errors.As(c8.stop, &b):
+			return nil // Halted until a reset or another ROM
+		case inpututil.IsKeyJustPressed(ui.CONTINUE_KEY):
+			c8.stop = nil
+		case inpututil.IsKeyJustPressed(ui.STEP_KEY):
+			steps = 1
+		default:
+			return nil
+		}
+	}
+
+	// ran counts the instructions run, not the one stopping the machine
+	ran := 0
+
+	for ; ran < steps; ran++ {
+		err := c8.cpu.Run()
+
+		if c8.debuggerStopped(err) {
+			break
+		}
+
+		if errors.Is(err, cpu.ErrExit) {
+			c8.quit = true
+			break
+		}
+
+		// Breakpoints, unsupported opcodes and stack errors stop the
+		// machine and show the debug overlay
+		if err != nil {
+			c8.stop = err
+			c8.debug = true
+			break
 		}
 
 		if c8.cpu.SoundTimer > 0 && c8.audioPlayer != nil {
@@ -39,50 +160,363 @@ func (c8 *Chip8) Update() error {
 		}
 	}
 
+	c8.ips.add(ran)
+
+	if err := c8.tools.Trace.Flush(); err != nil {
+		log.Print(err)
+	}
+
+	if c8.cpu.Sample != c8.sample {
+		c8.playSample(c8.cpu.Sample)
+	}
+
 	return nil
 }
 
+// load starts rom on a new machine of the configured platform, save states
+// of the previous ROM are dropped.
+func (c8 *Chip8) load(rom []byte, title string) error {
+	platform, err := cpu.ParsePlatform(c8.cfg.Platform)
+
+	if err != nil {
+		return err
+	}
+
+	c := cpu.NewCpuForPlatform(platform)
+	c.LoadROM(rom)
+
+	c8.rom = rom
+	c8.title = title
+	c8.states = [SAVE_SLOTS]*cpu.CPU{}
+	c8.setCPU(&c)
+
+	ebiten.SetWindowTitle(title)
+
+	return nil
+}
+
+// launch loads a ROM requested by a debugger and closes the menus.
+func (c8 *Chip8) launch(rom []byte, title string) error {
+	if err := c8.load(rom, title); err != nil {
+		return err
+	}
+
+	c8.browser = nil
+	c8.closeMenu()
+
+	return nil
+}
+
+// setCPU replaces the running machine, for resets and save states.
+func (c8 *Chip8) setCPU(c *cpu.CPU) {
+	c8.cpu = c
+	c8.applyQuirks()
+
+	c8.renderer.Zones = c.Zones
+	c8.cpu.Trace = c8.tools.Trace
+	c8.cpu.Breakpoints = c8.tools.Breakpoints
+	c8.cpu.Calls = c8.tools.Calls
+	c8.cpu.Profile = c8.tools.Profile
+
+	for _, d := range c8.tools.Debuggers {
+		d.SetCPU(c)
+	}
+
+	c8.stop = nil
+
+	if c8.memory != nil {
+		c.Memory().Recorder = c8.memory.Heat.Record
+	}
+}
+
+// applyQuirks sets the configured quirks on the running machine.
+func (c8 *Chip8) applyQuirks() {
+	c8.cpu.Quirks = cpu.Quirks{
+		HalfScrollLores: c8.cfg.Quirks.HalfScrollLores,
+		CollisionRows:   c8.cfg.Quirks.CollisionRows,
+		KeyPressOnly:    c8.cfg.Quirks.KeyPressOnly,
+	}
+}
+
+// toggleMemoryViewer shows or hides the memory viewer, memory accesses are
+// only recorded while it is shown.
+func (c8 *Chip8) toggleMemoryViewer() {
+	if c8.memory != nil {
+		c8.memory = nil
+		c8.cpu.Memory().Recorder = nil
+		return
+	}
+
+	c8.memory = ui.NewMemoryViewer(c8.cpu.Memory().Size())
+	c8.cpu.Memory().Recorder = c8.memory.Heat.Record
+}
+
+// updateRemap runs the remapping screen, the emulation is paused until it
+// closes and the new bindings are written to the configuration file.
+func (c8 *Chip8) updateRemap() {
+	switch c8.remap.Update() {
+	case ui.SCREEN_DONE:
+		c8.keyboard.SetBindings(c8.remap.Keyboard)
+		c8.gamepad.Bindings = c8.remap.Gamepad
+		c8.remap = nil
+
+		input.SaveBindings(&c8.cfg.Controls, c8.keyboard, c8.gamepad.Bindings)
+
+		// The first keypad may now use keys of the second one
+		keypad2, err := input.Keypad2Bindings(c8.cfg.Controls, c8.keyboard.Bindings)
+
+		if err != nil {
+			log.Print(err)
+			keypad2 = nil
+		}
+
+		c8.keypad2 = input.Sources{input.NewKeyboard(keypad2)}
+
+		if c8.cfg.Path == "" {
+			return
+		}
+
+		if err := c8.cfg.Save(c8.cfg.Path); err != nil {
+			log.Print(err)
+		}
+	case ui.SCREEN_CANCELED:
+		c8.remap = nil
+	}
+}
+
+func (c8 *Chip8) playSample(s *cpu.Sample) {
+	c8.sample = s
+
+	if c8.samplePlayer != nil {
+		c8.samplePlayer.Close()
+		c8.samplePlayer = nil
+	}
+
+	if s == nil {
+		return
+	}
+
+	p, err := audio.NewSamplePlayer(s.Rate, s.Data, s.Loop)
+
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	c8.samplePlayer = p
+	c8.samplePlayer.Play()
+}
+
 func (c8 *Chip8) Draw(screen *ebiten.Image) {
-	screen.Fill(color.NRGBA{23, 20, 33, 1})
-
-	for h := 0; h < c8.cpu.Graphics.Height; h++ {
-		for w := 0; w < c8.cpu.Graphics.Width; w++ {
-			if c8.cpu.Graphics.GetPixel(h, w) == 0x01 {
-				imgOpts := &ebiten.DrawImageOptions{}
-				imgOpts.GeoM.Translate(float64(w*10), float64(h*10))
-				screen.DrawImage(c8.square, imgOpts)
-			}
+	if c8.cpu.MEGACHIP_MODE {
+		c8.drawColor(screen)
+	} else {
+		c8.drawMono(screen)
+	}
+
+	c8.touch.Draw(screen)
+
+	switch {
+	case c8.memory != nil:
+		c8.memory.Draw(screen, c8.cpu.Memory(), c8.cpu.PC(), c8.cpu.Addr())
+	case c8.debug:
+		ui.DrawPanel(screen, c8.debugLines())
+	}
+
+	switch {
+	case c8.remap != nil:
+		c8.remap.Draw(screen)
+	case c8.browser != nil:
+		c8.browser.Draw(screen)
+	case c8.menuOpen:
+		c8.menu.Draw(screen)
+	}
+}
+
+func (c8 *Chip8) drawMono(screen *ebiten.Image) {
+	g := c8.cpu.Graphics
+
+	if !c8.renderer.Idle(g) {
+		frame := c8.renderer.Render(g)
+
+		if c8.display == nil || c8.display.Bounds() != frame.Bounds() {
+			c8.display = ebiten.NewImage(g.Width, g.Height)
 		}
+
+		c8.display.WritePixels(frame.Pix)
 	}
+
+	g.EndFrame()
+
+	target := screen
+
+	if c8.filter != nil {
+		if c8.canvas == nil || c8.canvas.Bounds() != screen.Bounds() {
+			c8.canvas = ebiten.NewImage(screen.Bounds().Dx(), screen.Bounds().Dy())
+		}
+
+		target = c8.canvas
+	}
+
+	target.Fill(c8.renderer.Background)
+	target.DrawImage(c8.display, fitScreen(g.Width, g.Height, c8.touch.Display(screen.Bounds())))
+
+	if c8.filter != nil {
+		shaderOpts := &ebiten.DrawRectShaderOptions{}
+		shaderOpts.Images[0] = c8.canvas
+		screen.DrawRectShader(screen.Bounds().Dx(), screen.Bounds().Dy(), c8.filter, shaderOpts)
+	}
+}
+
+// drawColor presents the MEGA-CHIP display, filters and persistence only
+// apply to the monochrome display.
+func (c8 *Chip8) drawColor(screen *ebiten.Image) {
+	g := c8.cpu.Color
+
+	if c8.colorDisplay == nil {
+		c8.colorDisplay = ebiten.NewImage(g.Width, g.Height)
+	}
+
+	if g.Dirty() {
+		c8.colorDisplay.WritePixels(g.Image().Pix)
+		g.EndFrame()
+	}
+
+	opts := fitScreen(g.Width, g.Height, c8.touch.Display(screen.Bounds()))
+	opts.ColorScale.ScaleAlpha(float32(g.Alpha) / 0xFF)
+
+	screen.Fill(color.Black)
+	screen.DrawImage(c8.colorDisplay, opts)
 }
 
 func (c8 *Chip8) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return c8.cpu.Graphics.Width * 10, c8.cpu.Graphics.Height * 10
+	c8.touch.Resize(outsideWidth, outsideHeight)
+
+	return outsideWidth, outsideHeight
 }
 
-func RunChip8(rom []byte, title string) {
-	c := cpu.NewCpu()
-	c.LoadROM(rom)
+// fitScreen scales an image of w x h pixels to the largest size that fits
+// area keeping its aspect ratio, centered and without smoothing.
+func fitScreen(w int, h int, area image.Rectangle) *ebiten.DrawImageOptions {
+	sw := float64(area.Dx())
+	sh := float64(area.Dy())
+	scale := math.Min(sw/float64(w), sh/float64(h))
+
+	opts := &ebiten.DrawImageOptions{Filter: ebiten.FilterNearest}
+	opts.GeoM.Scale(scale, scale)
+	opts.GeoM.Translate(float64(area.Min.X)+(sw-float64(w)*scale)/2, float64(area.Min.Y)+(sh-float64(h)*scale)/2)
+
+	return opts
+}
 
-	sqr := ebiten.NewImage(10, 10)
-	sqr.Fill(color.RGBA{51, 209, 122, 1})
+// Debugger is a remote debugger served by the frontend, e.g. a GDB or DAP
+// server. It is only called from the frontend loop.
+type Debugger interface {
+	Poll() bool             // Handles pending requests, false while the debugger halts the machine
+	Stopped(err error) bool // Reports an error of CPU.Run, false when no debugger takes it
+	SetCPU(c *cpu.CPU)      // Serves a new machine after resets, loads and save states
+}
 
+// Launcher is a Debugger that also starts ROMs, e.g. for editors.
+type Launcher interface {
+	Debugger
+	SetLoad(load func(rom []byte, title string) error)
+}
+
+// debuggerStopped reports err to the debuggers until one of them takes it.
+func (c8 *Chip8) debuggerStopped(err error) bool {
+	for _, d := range c8.tools.Debuggers {
+		if d.Stopped(err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DebugOptions are the debugging tools attached to every machine run, nil
+// fields are off.
+type DebugOptions struct {
+	Trace       *cpu.Trace       // Logs every instruction
+	Breakpoints *cpu.Breakpoints // Stops the machine and shows the debug overlay, F5 continues and F6 steps
+	Debuggers   []Debugger       // Stops go to the attached debuggers instead of the overlay
+	Symbols     *disasm.Symbols  // Names addresses in the debug overlay
+	Calls       *cpu.CallLog     // Last calls and returns, shown by the debug overlay when stopped
+	Profile     *cpu.Profile     // Counts every instruction
+}
+
+// RunChip8 opens the emulator window, the menu is shown first when rom is
+// nil so a ROM can be picked from cfg.Roms. It returns when the window is
+// closed or the ROM exits.
+func RunChip8(rom []byte, title string, cfg *config.Config, tools DebugOptions) error {
 	p, err := audio.NewAudioPlayer()
 
 	if err != nil {
 		log.Print(err)
 	}
 
+	keyboard, err := input.NewKeyboardFromConfig(cfg.Controls)
+
+	if err != nil {
+		return err
+	}
+
+	gamepad, err := input.GamepadBindings(cfg.Controls)
+
+	if err != nil {
+		return err
+	}
+
+	keypad2, err := input.Keypad2Bindings(cfg.Controls, keyboard.Bindings)
+
+	if err != nil {
+		return err
+	}
+
+	filter, err := newFilterShader(cfg.Effects.Filter)
+
+	if err != nil {
+		log.Print(err)
+	}
+
 	c8 := &Chip8{
-		square:      sqr,
-		cpu:         &c,
+		renderer:    render.NewRenderer(1, config.Effects{Persistence: cfg.Effects.Persistence}),
+		filter:      filter,
 		audioPlayer: p,
+		cfg:         cfg,
+		tools:       tools,
+		speed:       cfg.Speed,
+		keyboard:    keyboard,
+		gamepad:     input.NewGamepad(gamepad),
+		keypad2:     input.Sources{input.NewKeyboard(keypad2)},
+		touch:       ui.NewTouchKeypad(),
 	}
 
-	ebiten.SetWindowSize(c.Graphics.Width*10, c.Graphics.Height*10)
-	ebiten.SetWindowTitle(title)
+	if c8.speed <= 0 {
+		c8.speed = cpu.SPEED
+	}
+
+	c8.keypad = input.Sources{c8.keyboard, c8.gamepad, c8.touch}
+
+	for _, d := range tools.Debuggers {
+		if l, ok := d.(Launcher); ok {
+			l.SetLoad(c8.launch)
+		}
+	}
 
-	if err := ebiten.RunGame(c8); err != nil {
-		log.Fatal(err)
+	c8.menu = c8.newMenu()
+	c8.renderer.SetPalette(render.PaletteByName(cfg.Palette))
+
+	if title == "" {
+		title = "CHIP-8"
+	}
+
+	if err := c8.load(rom, title); err != nil {
+		return err
 	}
+
+	ebiten.SetWindowSize(c8.cpu.Graphics.Width*10, c8.cpu.Graphics.Height*10)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+
+	return ebiten.RunGame(c8)
 }
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+const (
+	FILTER_NONE      = "none"
+	FILTER_SCANLINES = "scanlines"
+	FILTER_CRT       = "crt"
+)
+
+const (
+	LAYOUT_QWERTY = "qwerty"
+	LAYOUT_NUMPAD = "numpad"
+	LAYOUT_HEX    = "hex"
+)
+
+const (
+	KEYBOARD_SCANCODE  = "scancode"
+	KEYBOARD_CHARACTER = "character"
+)
+
+type Effects struct {
+	Persistence int    `json:"persistence"` // Frames a pixel takes to fade out, 0 disables it
+	Filter      string `json:"filter"`      // none, scanlines or crt
+}
+
+// Controls binds device buttons to CHIP-8 keys (0 to F), buttons are named
+// as in the README. An empty map keeps the default bindings of the device.
+type Controls struct {
+	Layout    string            `json:"layout"` // Preset keyboard bindings: qwerty, numpad or hex
+	Mode      string            `json:"mode"`   // scancode binds key positions, character binds the characters printed on the keys
+	Keyboard  map[string]string `json:"keyboard,omitempty"`
+	Keyboard2 map[string]string `json:"keyboard2,omitempty"` // Second CHIP-8X keypad, ebiten.Key names
+	Gamepad   map[string]string `json:"gamepad,omitempty"`
+}
+
+// Quirks overrides CPU behaviors that differ between implementations.
+type Quirks struct {
+	HalfScrollLores bool `json:"halfScrollLores"` // Low resolution scrolls move half the high resolution distance
+	CollisionRows   bool `json:"collisionRows"`   // VF holds the number of colliding rows in high resolution
+	KeyPressOnly    bool `json:"keyPressOnly"`    // FX0A returns when a key is pressed instead of released
+}
+
+type Config struct {
+	Platform string   `json:"platform"` // chip8, chip8e, chip8x, chip10, schip or megachip
+	Roms     string   `json:"roms"`     // Directory opened by the ROM browser
+	Speed    int      `json:"speed"`    // Instructions per frame
+	Palette  string   `json:"palette"`  // Display colors, see render.Palettes
+	Effects  Effects  `json:"effects"`
+	Controls Controls `json:"controls"`
+	Quirks   Quirks   `json:"quirks"`
+
+	Path string `json:"-"` // File the configuration was loaded from, empty for the defaults
+}
+
+func Default() *Config {
+	return &Config{
+		Platform: "schip",
+		Roms:     "cli/roms",
+		Speed:    11,
+		Palette:  "green",
+		Effects: Effects{
+			Persistence: 0,
+			Filter:      FILTER_NONE,
+		},
+		Controls: Controls{
+			Layout: LAYOUT_QWERTY,
+			Mode:   KEYBOARD_SCANCODE,
+		},
+		Quirks: Quirks{
+			HalfScrollLores: true,
+			CollisionRows:   true,
+		},
+	}
+}
+
+// Load reads the configuration file at path, missing fields keep their
+// default values and a missing file results in the default configuration.
+func Load(path string) (*Config, error) {
+	c := Default()
+	c.Path = path
+
+	data, err := os.ReadFile(path)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Config) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,72 @@
+package config_test
+
+import (
+	"path"
+	"testing"
+
+	"github.com/gaoliveira21/chip8/core/config"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	c, err := config.Load(path.Join(t.TempDir(), "missing.json"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Effects.Filter != config.FILTER_NONE {
+		t.Errorf("Effects.Filter = %s; expected %s", c.Effects.Filter, config.FILTER_NONE)
+	}
+
+	if c.Controls.Mode != config.KEYBOARD_SCANCODE {
+		t.Errorf("Controls.Mode = %s; expected %s", c.Controls.Mode, config.KEYBOARD_SCANCODE)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	p := path.Join(t.TempDir(), "config.json")
+
+	c := config.Default()
+	c.Effects.Persistence = 6
+	c.Effects.Filter = config.FILTER_CRT
+
+	if err := c.Save(p); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := config.Load(p)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Effects != c.Effects {
+		t.Errorf("Effects = %+v; expected %+v", loaded.Effects, c.Effects)
+	}
+}
+
+func TestSaveControls(t *testing.T) {
+	p := path.Join(t.TempDir(), "config.json")
+
+	c := config.Default()
+	c.Controls.Keyboard = map[string]string{"ArrowUp": "5", "Space": "A"}
+	c.Controls.Gamepad = map[string]string{"A": "6"}
+
+	if err := c.Save(p); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := config.Load(p)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Path != p {
+		t.Errorf("Path = %s; expected %s", loaded.Path, p)
+	}
+
+	if loaded.Controls.Keyboard["Space"] != "A" || loaded.Controls.Gamepad["A"] != "6" {
+		t.Errorf("Controls = %+v; expected %+v", loaded.Controls, c.Controls)
+	}
+}
@@ -0,0 +1,279 @@
+// Package conformance runs test ROMs and games headless with scripted input
+// and compares their screen with golden images or hashes.
+package conformance
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/graphics"
+	"github.com/gaoliveira21/chip8/core/headless"
+)
+
+// Input holds a key down from a frame.
+type Input struct {
+	Frame  int
+	Key    uint8
+	Frames int // Held for, 1 when 0
+}
+
+// Case is a test ROM run on a platform.
+type Case struct {
+	Name     string // Also the golden image name
+	ROM      string // Path
+	Platform cpu.Platform
+	Quirks   cpu.Quirks
+	Frames   int
+	Memory   map[uint16]byte // Set before running, e.g. 0x1FF to pick a test in the Timendus menus
+	Inputs   []Input         // In frame order
+	Seed     uint64          // Of the random numbers of CXNN
+	Start    uint16          // Load address and first PC, the platform start address when 0
+}
+
+// Run runs the case and returns the screen at the last frame.
+func (c Case) Run() (*image.Gray, error) {
+	r, err := c.play(func(*headless.Runner) {})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Screenshot(r.CPU.Graphics), nil
+}
+
+// Hashes runs the case until the last checkpoint and returns the SHA-1 of
+// the screen at every checkpoint frame, in order.
+func (c Case) Hashes(checkpoints []int) ([]string, error) {
+	hashes := []string{}
+	c.Frames = checkpoints[len(checkpoints)-1]
+
+	_, err := c.play(func(r *headless.Runner) {
+		if len(hashes) < len(checkpoints) && r.Frame >= checkpoints[len(hashes)] {
+			hashes = append(hashes, Hash(r.CPU.Graphics))
+		}
+	})
+
+	return hashes, err
+}
+
+// play runs the frames of the case, calling frame after each of them.
+func (c Case) play(frame func(r *headless.Runner)) (*headless.Runner, error) {
+	rom, err := os.ReadFile(c.ROM)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r := headless.NewRunner(c.Platform, rom)
+	r.CPU.Quirks = c.Quirks
+	r.CPU.Seed(c.Seed)
+
+	// The ROM is moved, e.g. ROMs built for 0x200 run on CHIP-8X at 0x200
+	if c.Start != 0 {
+		from := uint32(c.Platform.StartAddress())
+
+		for i := range rom {
+			r.CPU.Memory().Store(from+uint32(i), 0x00)
+		}
+
+		for i, b := range rom {
+			r.CPU.Memory().Store(uint32(c.Start)+uint32(i), b)
+		}
+
+		r.CPU.SetPC(c.Start)
+	}
+
+	for addr, b := range c.Memory {
+		r.CPU.Memory().Store(uint32(addr), b)
+	}
+
+	inputs := c.Inputs
+	held := map[uint8]int{} // Frame of the release of every key down
+
+	for r.Frame < c.Frames {
+		if len(inputs) > 0 && inputs[0].Frame <= r.Frame {
+			r.Press(inputs[0].Key)
+			held[inputs[0].Key] = r.Frame + max(inputs[0].Frames, 1)
+			inputs = inputs[1:]
+			continue
+		}
+
+		if err := r.Step(); err != nil {
+			return nil, fmt.Errorf("%s: frame %d: %w", c.Name, r.Frame, err)
+		}
+
+		for key, until := range held {
+			if r.Frame >= until {
+				r.Release(key)
+				delete(held, key)
+			}
+		}
+
+		frame(r)
+	}
+
+	return r, nil
+}
+
+// Hash returns the SHA-1 of the pixels of the display.
+func Hash(g *graphics.Graphics) string {
+	h := sha1.New()
+
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			h.Write([]byte{g.GetPixel(y, x)})
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// LoadMovie reads recorded input, one key press per line: the frame, the
+// key in hexadecimal and how many frames it is held, 1 when missing. Text
+// after # is a comment.
+//
+//	120 5 10 # Start
+func LoadMovie(path string) ([]Input, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := []Input{}
+
+	for n, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		in, err := parseInput(fields)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n+1, err)
+		}
+
+		if len(inputs) > 0 && in.Frame < inputs[len(inputs)-1].Frame {
+			return nil, fmt.Errorf("%s:%d: frame %d is before the previous input", path, n+1, in.Frame)
+		}
+
+		inputs = append(inputs, in)
+	}
+
+	return inputs, nil
+}
+
+func parseInput(fields []string) (Input, error) {
+	if len(fields) < 2 || len(fields) > 3 {
+		return Input{}, fmt.Errorf("expected frame, key and frames, got %q", strings.Join(fields, " "))
+	}
+
+	frame, err := strconv.Atoi(fields[0])
+
+	if err != nil {
+		return Input{}, fmt.Errorf("frame %q: %w", fields[0], err)
+	}
+
+	key, err := strconv.ParseUint(fields[1], 16, 4)
+
+	if err != nil {
+		return Input{}, fmt.Errorf("key %q: %w", fields[1], err)
+	}
+
+	frames := 1
+
+	if len(fields) == 3 {
+		if frames, err = strconv.Atoi(fields[2]); err != nil {
+			return Input{}, fmt.Errorf("frames %q: %w", fields[2], err)
+		}
+	}
+
+	return Input{Frame: frame, Key: uint8(key), Frames: frames}, nil
+}
+
+// Screenshot returns the display, lit pixels white.
+func Screenshot(g *graphics.Graphics) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, g.Width, g.Height))
+
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			if g.GetPixel(y, x) != 0 {
+				img.SetGray(x, y, color.Gray{Y: 0xFF})
+			}
+		}
+	}
+
+	return img
+}
+
+// Diff returns the number of pixels that differ, every pixel when the
+// sizes differ.
+func Diff(a *image.Gray, b *image.Gray) int {
+	if a.Bounds() != b.Bounds() {
+		return max(len(a.Pix), len(b.Pix))
+	}
+
+	n := 0
+
+	for i := range a.Pix {
+		if a.Pix[i] != b.Pix[i] {
+			n++
+		}
+	}
+
+	return n
+}
+
+// LoadGolden reads a golden image.
+func LoadGolden(path string) (*image.Gray, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	img, err := png.Decode(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	gray := image.NewGray(img.Bounds())
+
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			gray.Set(x, y, img.At(x, y))
+		}
+	}
+
+	return gray, nil
+}
+
+// SaveGolden writes an image as PNG.
+func SaveGolden(path string, img image.Image) error {
+	f, err := os.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,98 @@
+package conformance_test
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"github.com/gaoliveira21/chip8/core/conformance"
+	"github.com/gaoliveira21/chip8/core/cpu"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden images and hashes")
+
+const (
+	ROMS     = "../../cli/roms/test/"
+	TESTDATA = "testdata/roms/"
+)
+
+// taps presses keys one after the other, every second from frame 30, as the
+// menus of the test ROMs expect.
+func taps(keys ...uint8) []conformance.Input {
+	inputs := []conformance.Input{}
+
+	for i, k := range keys {
+		inputs = append(inputs, conformance.Input{Frame: 30 + 60*i, Key: k, Frames: 5})
+	}
+
+	return inputs
+}
+
+var cases = []conformance.Case{
+	{Name: "corax-chip8", ROM: ROMS + "test_opcode.ch8", Platform: cpu.PLATFORM_CHIP8, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "corax-schip", ROM: ROMS + "test_opcode.ch8", Platform: cpu.PLATFORM_SCHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "corax-plus-chip8", ROM: ROMS + "test_opcode2.ch8", Platform: cpu.PLATFORM_CHIP8, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "corax-plus-schip", ROM: ROMS + "test_opcode2.ch8", Platform: cpu.PLATFORM_SCHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "schip-test", ROM: ROMS + "schip-test.ch8", Platform: cpu.PLATFORM_SCHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "schip-font", ROM: ROMS + "schip-font.ch8", Platform: cpu.PLATFORM_SCHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "scrolling-legacy-lores", ROM: ROMS + "scrolling.ch8", Platform: cpu.PLATFORM_SCHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 600, Inputs: taps(0x1, 0x1, 0x2)},
+	{Name: "scrolling-modern-lores", ROM: ROMS + "scrolling.ch8", Platform: cpu.PLATFORM_SCHIP, Quirks: cpu.MODERN_QUIRKS, Frames: 600, Inputs: taps(0x1, 0x1, 0x1)},
+	{Name: "scrolling-hires", ROM: ROMS + "scrolling.ch8", Platform: cpu.PLATFORM_SCHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 600, Inputs: taps(0x1, 0x2)},
+
+	// The CHIP-8 tests on the extensions, and SUPER-CHIP on MEGA-CHIP
+	{Name: "corax-chip8e", ROM: ROMS + "test_opcode.ch8", Platform: cpu.PLATFORM_CHIP8E, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "corax-plus-chip8e", ROM: ROMS + "test_opcode2.ch8", Platform: cpu.PLATFORM_CHIP8E, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "corax-chip10", ROM: ROMS + "test_opcode.ch8", Platform: cpu.PLATFORM_CHIP10, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "corax-plus-chip10", ROM: ROMS + "test_opcode2.ch8", Platform: cpu.PLATFORM_CHIP10, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "corax-megachip", ROM: ROMS + "test_opcode.ch8", Platform: cpu.PLATFORM_MEGACHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "corax-plus-megachip", ROM: ROMS + "test_opcode2.ch8", Platform: cpu.PLATFORM_MEGACHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "schip-test-megachip", ROM: ROMS + "schip-test.ch8", Platform: cpu.PLATFORM_MEGACHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+
+	// CHIP-8X loads at 0x300, chip8x.ch8 steps the background color (02A0),
+	// draws the digits 0 to B, a 3 added with 5XY1 and colors the top half
+	// with BXY0
+	{Name: "chip8x", ROM: TESTDATA + "chip8x.ch8", Platform: cpu.PLATFORM_CHIP8X, Quirks: cpu.LEGACY_QUIRKS, Frames: 30},
+
+	// CHIP-8 ROMs run on CHIP-8X moved back to 0x200
+	{Name: "corax-chip8x", ROM: ROMS + "test_opcode.ch8", Platform: cpu.PLATFORM_CHIP8X, Quirks: cpu.LEGACY_QUIRKS, Frames: 400, Start: 0x200},
+}
+
+func TestConformance(t *testing.T) {
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			compare(t, c)
+		})
+	}
+}
+
+// compare runs c and compares its screen with testdata/NAME.png, or
+// records it with -update.
+func compare(t *testing.T, c conformance.Case) {
+	actual, err := c.Run()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", c.Name+".png")
+
+	if *update {
+		if err := conformance.SaveGolden(golden, actual); err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	expected, err := conformance.LoadGolden(golden)
+
+	if err != nil {
+		t.Fatalf("%v; run go test ./core/conformance -update to record it", err)
+	}
+
+	if n := conformance.Diff(actual, expected); n != 0 {
+		path := filepath.Join(t.TempDir(), c.Name+".png")
+		conformance.SaveGolden(path, actual)
+		t.Errorf("%d pixels differ from %s, the screen is in %s", n, golden, path)
+	}
+}
@@ -0,0 +1,119 @@
+package conformance_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gaoliveira21/chip8/core/conformance"
+	"github.com/gaoliveira21/chip8/core/cpu"
+)
+
+// SEED makes the random numbers of the games, and so their goldens,
+// reproducible.
+const SEED = 0
+
+// checkpoints are the frames whose screen is hashed, up to 30 seconds.
+var checkpoints = []int{60, 300, 600, 900, 1200, 1500, 1800}
+
+// platforms of the games that use SUPER-CHIP instructions, the others run
+// on CHIP-8.
+var platforms = map[string]cpu.Platform{
+	"BLINKY":        cpu.PLATFORM_SCHIP,
+	"SINGLE_DRAGON": cpu.PLATFORM_SCHIP,
+	"SPACEFIGHT":    cpu.PLATFORM_SCHIP,
+}
+
+// TestGames plays the movie of every ROM of cli/roms and compares the
+// screen at the checkpoints with the hashes recorded in testdata/games.
+func TestGames(t *testing.T) {
+	roms, err := filepath.Glob("../../cli/roms/*.ch8")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rom := range roms {
+		name := strings.TrimSuffix(filepath.Base(rom), ".ch8")
+
+		t.Run(name, func(t *testing.T) {
+			movie := filepath.Join("testdata", "games", name+".movie")
+			inputs, err := conformance.LoadMovie(movie)
+
+			if err != nil {
+				t.Fatalf("%v; record the input of the ROM in %s", err, movie)
+			}
+
+			platform, ok := platforms[name]
+
+			if !ok {
+				platform = cpu.PLATFORM_CHIP8
+			}
+
+			c := conformance.Case{Name: name, ROM: rom, Platform: platform, Quirks: cpu.LEGACY_QUIRKS, Inputs: inputs, Seed: SEED}
+			actual, err := c.Hashes(checkpoints)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", "games", name+".golden")
+
+			if *update {
+				if err := saveHashes(golden, actual); err != nil {
+					t.Fatal(err)
+				}
+
+				return
+			}
+
+			expected, err := loadHashes(golden)
+
+			if err != nil {
+				t.Fatalf("%v; run go test ./core/conformance -update to record it", err)
+			}
+
+			for i, frame := range checkpoints {
+				if e := expected[frame]; actual[i] != e {
+					t.Errorf("frame %d: hash = %s; expected %s", frame, actual[i], e)
+				}
+			}
+		})
+	}
+}
+
+// loadHashes reads a golden file of "frame hash" lines.
+func loadHashes(path string) (map[int]string, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := map[int]string{}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var frame int
+		var hash string
+
+		if _, err := fmt.Sscanf(line, "%d %s", &frame, &hash); err != nil {
+			return nil, fmt.Errorf("%s: %q: %w", path, line, err)
+		}
+
+		hashes[frame] = hash
+	}
+
+	return hashes, nil
+}
+
+func saveHashes(path string, hashes []string) error {
+	var b strings.Builder
+
+	for i, frame := range checkpoints {
+		fmt.Fprintf(&b, "%d %s\n", frame, hashes[i])
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
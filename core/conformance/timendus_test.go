@@ -0,0 +1,49 @@
+//go:build timendus
+
+package conformance_test
+
+import (
+	"testing"
+
+	"github.com/gaoliveira21/chip8/core/conformance"
+	"github.com/gaoliveira21/chip8/core/cpu"
+)
+
+// TIMENDUS holds the Timendus test suite, which is not bundled: copy its
+// ROMs there and run go test -tags timendus ./core/conformance -update once
+// to record the goldens.
+const TIMENDUS = "../../cli/roms/test/timendus/"
+
+// The Timendus suite picks the platform of the flags, quirks and keypad
+// tests from 0x1FF when it is set, 1 for CHIP-8 and 2 for SUPER-CHIP. The
+// CHIP-8 variants are tested as CHIP-8 and MEGA-CHIP as SUPER-CHIP, the
+// ROMs are built for 0x200 so they run there on CHIP-8X.
+var timendus = []conformance.Case{
+	{Name: "timendus-logo", ROM: TIMENDUS + "1-chip8-logo.ch8", Platform: cpu.PLATFORM_CHIP8, Quirks: cpu.LEGACY_QUIRKS, Frames: 60},
+	{Name: "timendus-ibm", ROM: TIMENDUS + "2-ibm-logo.ch8", Platform: cpu.PLATFORM_CHIP8, Quirks: cpu.LEGACY_QUIRKS, Frames: 60},
+	{Name: "timendus-corax-plus", ROM: TIMENDUS + "3-corax+.ch8", Platform: cpu.PLATFORM_CHIP8, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "timendus-flags-chip8", ROM: TIMENDUS + "4-flags.ch8", Platform: cpu.PLATFORM_CHIP8, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "timendus-flags-schip", ROM: TIMENDUS + "4-flags.ch8", Platform: cpu.PLATFORM_SCHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "timendus-flags-chip8e", ROM: TIMENDUS + "4-flags.ch8", Platform: cpu.PLATFORM_CHIP8E, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "timendus-flags-chip10", ROM: TIMENDUS + "4-flags.ch8", Platform: cpu.PLATFORM_CHIP10, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "timendus-flags-chip8x", ROM: TIMENDUS + "4-flags.ch8", Platform: cpu.PLATFORM_CHIP8X, Quirks: cpu.LEGACY_QUIRKS, Frames: 400, Start: 0x200},
+	{Name: "timendus-flags-megachip", ROM: TIMENDUS + "4-flags.ch8", Platform: cpu.PLATFORM_MEGACHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 400},
+	{Name: "timendus-quirks-chip8", ROM: TIMENDUS + "5-quirks.ch8", Platform: cpu.PLATFORM_CHIP8, Quirks: cpu.LEGACY_QUIRKS, Frames: 1200, Memory: map[uint16]byte{0x1FF: 1}},
+	{Name: "timendus-quirks-schip", ROM: TIMENDUS + "5-quirks.ch8", Platform: cpu.PLATFORM_SCHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 1200, Memory: map[uint16]byte{0x1FF: 2}},
+	{Name: "timendus-quirks-chip8e", ROM: TIMENDUS + "5-quirks.ch8", Platform: cpu.PLATFORM_CHIP8E, Quirks: cpu.LEGACY_QUIRKS, Frames: 1200, Memory: map[uint16]byte{0x1FF: 1}},
+	{Name: "timendus-quirks-chip10", ROM: TIMENDUS + "5-quirks.ch8", Platform: cpu.PLATFORM_CHIP10, Quirks: cpu.LEGACY_QUIRKS, Frames: 1200, Memory: map[uint16]byte{0x1FF: 1}},
+	{Name: "timendus-quirks-chip8x", ROM: TIMENDUS + "5-quirks.ch8", Platform: cpu.PLATFORM_CHIP8X, Quirks: cpu.LEGACY_QUIRKS, Frames: 1200, Memory: map[uint16]byte{0x1FF: 1}, Start: 0x200},
+	{Name: "timendus-quirks-megachip", ROM: TIMENDUS + "5-quirks.ch8", Platform: cpu.PLATFORM_MEGACHIP, Quirks: cpu.LEGACY_QUIRKS, Frames: 1200, Memory: map[uint16]byte{0x1FF: 2}},
+	{Name: "timendus-keypad-ex9e", ROM: TIMENDUS + "6-keypad.ch8", Platform: cpu.PLATFORM_CHIP8, Quirks: cpu.LEGACY_QUIRKS, Frames: 300, Memory: map[uint16]byte{0x1FF: 1}, Inputs: taps(0x5, 0xA)},
+	{Name: "timendus-keypad-fx0a", ROM: TIMENDUS + "6-keypad.ch8", Platform: cpu.PLATFORM_CHIP8, Quirks: cpu.LEGACY_QUIRKS, Frames: 300, Memory: map[uint16]byte{0x1FF: 3}, Inputs: taps(0x5)},
+}
+
+// TestTimendus fails when a ROM or a golden is missing, the tag asks for
+// the suite.
+func TestTimendus(t *testing.T) {
+	for _, c := range timendus {
+		t.Run(c.Name, func(t *testing.T) {
+			compare(t, c)
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package cpu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	BREAK_PC        = iota // Before the instruction at Start runs
+	BREAK_WRITE            // After an instruction writes between Start and End
+	BREAK_CONDITION        // Before the instruction for which Condition becomes true
+)
+
+type Breakpoint struct {
+	ID        int
+	Kind      int
+	Start     uint32 // Inclusive address range, a single address for BREAK_PC
+	End       uint32
+	Condition *Condition // Must hold for the breakpoint to hit, optional for BREAK_PC and BREAK_WRITE
+	HitCount  int        // Stops from the HitCount-th hit on, 0 stops on every hit
+	Hits      int
+	Disabled  bool
+
+	held bool // Condition held at the previous instruction, for BREAK_CONDITION
+}
+
+func (b *Breakpoint) String() string {
+	var s string
+
+	switch b.Kind {
+	case BREAK_PC:
+		s = fmt.Sprintf("%04X", b.Start)
+	case BREAK_WRITE:
+		s = fmt.Sprintf("write %04X", b.Start)
+
+		if b.End != b.Start {
+			s += fmt.Sprintf("-%04X", b.End)
+		}
+	}
+
+	if b.HitCount > 0 {
+		s += fmt.Sprintf(" hits %d", b.HitCount)
+	}
+
+	if b.Condition != nil {
+		s += " if " + b.Condition.Source
+	}
+
+	return strings.TrimSpace(s)
+}
+
+// hit counts a hit and reports whether it stops the CPU.
+func (b *Breakpoint) hit(cpu *CPU) bool {
+	if b.Condition != nil && !b.Condition.Holds(cpu) {
+		return false
+	}
+
+	b.Hits++
+
+	return b.Hits >= b.HitCount
+}
+
+// ParseBreakpoint parses the breakpoints of the -break flag:
+//
+//	204              before the instruction at 0x204
+//	write 300-30F    after a write between 0x300 and 0x30F
+//	if VA == 0x10    when the condition becomes true
+//
+// Addresses are hexadecimal, "hits N" stops from the Nth hit on and "if"
+// adds a condition to addresses and writes, e.g. "204 hits 3 if V0 > 2".
+func ParseBreakpoint(spec string) (*Breakpoint, error) {
+	return ParseBreakpointFunc(spec, parseAddress)
+}
+
+// ParseBreakpointFunc is ParseBreakpoint with the addresses parsed by
+// resolve, to accept labels.
+func ParseBreakpointFunc(spec string, resolve func(string) (uint32, error)) (*Breakpoint, error) {
+	b := &Breakpoint{Kind: BREAK_CONDITION}
+	target, cond, found := strings.Cut(" "+strings.TrimSpace(spec), " if ")
+
+	if found {
+		c, err := ParseCondition(cond)
+
+		if err != nil {
+			return nil, err
+		}
+
+		b.Condition = c
+	}
+
+	fields := strings.Fields(target)
+
+	if n := len(fields); n >= 2 && fields[n-2] == "hits" {
+		count, err := strconv.Atoi(fields[n-1])
+
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("breakpoint %q: invalid hit count %q", spec, fields[n-1])
+		}
+
+		b.HitCount = count
+		fields = fields[:n-2]
+	}
+
+	var addr string
+
+	switch {
+	case len(fields) == 0:
+		if b.Condition == nil {
+			return nil, fmt.Errorf("breakpoint %q: expected an address or a condition", spec)
+		}
+
+		return b, nil
+	case len(fields) == 2 && fields[0] == "write":
+		b.Kind = BREAK_WRITE
+		addr = fields[1]
+	case len(fields) == 1:
+		b.Kind = BREAK_PC
+		addr = fields[0]
+	default:
+		return nil, fmt.Errorf("breakpoint %q: unexpected %q", spec, strings.Join(fields, " "))
+	}
+
+	start, end, ranged := strings.Cut(addr, "-")
+
+	if !ranged {
+		end = start
+	}
+
+	var err error
+
+	if b.Start, err = resolve(start); err != nil {
+		return nil, fmt.Errorf("breakpoint %q: invalid address %q", spec, addr)
+	}
+
+	if b.End, err = resolve(end); err != nil || b.End < b.Start || (b.Kind == BREAK_PC && ranged) {
+		return nil, fmt.Errorf("breakpoint %q: invalid address %q", spec, addr)
+	}
+
+	return b, nil
+}
+
+func parseAddress(s string) (uint32, error) {
+	n, err := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 24)
+	return uint32(n), err
+}
+
+// BreakError is returned by Run when a breakpoint stops the CPU.
+type BreakError struct {
+	Breakpoint *Breakpoint
+	PC         uint16 // Next instruction
+	Addr       uint32 // Address written, for BREAK_WRITE
+	Old        byte
+	Value      byte
+}
+
+func (e *BreakError) Error() string {
+	if e.Breakpoint.Kind == BREAK_WRITE {
+		return fmt.Sprintf("breakpoint %d: write %02X -> %02X at %04X, pc %04X", e.Breakpoint.ID, e.Old, e.Value, e.Addr, e.PC)
+	}
+
+	return fmt.Sprintf("breakpoint %d: %s, pc %04X", e.Breakpoint.ID, e.Breakpoint, e.PC)
+}
+
+// Breakpoints stops the CPU before or after instructions, set it as
+// CPU.Breakpoints. Run does not check anything when it is nil.
+type Breakpoints struct {
+	List []*Breakpoint
+
+	nextID  int
+	resumed bool        // Run the next instruction without checking it, after a stop before it
+	pending *BreakError // Write hit by the running instruction
+	cpu     *CPU        // Machine running the instruction, for the conditions of writes
+}
+
+// Add gives b an ID and adds it to the list.
+func (bs *Breakpoints) Add(b *Breakpoint) *Breakpoint {
+	bs.nextID++
+	b.ID = bs.nextID
+	bs.List = append(bs.List, b)
+
+	return b
+}
+
+// Remove deletes the breakpoint with the given ID and reports whether it
+// existed.
+func (bs *Breakpoints) Remove(id int) bool {
+	for i, b := range bs.List {
+		if b.ID == id {
+			bs.List = append(bs.List[:i], bs.List[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// before checks the breakpoints hit by the instruction about to run.
+func (bs *Breakpoints) before(cpu *CPU) error {
+	watch := false
+
+	for _, b := range bs.List {
+		watch = watch || (b.Kind == BREAK_WRITE && !b.Disabled)
+	}
+
+	bs.cpu = cpu
+	bs.pending = nil
+	cpu.mmu.Watch = nil
+
+	if watch {
+		cpu.mmu.Watch = bs.watch
+	}
+
+	if bs.resumed {
+		bs.resumed = false
+		return nil
+	}
+
+	var stop *Breakpoint
+
+	for _, b := range bs.List {
+		if b.Disabled {
+			continue
+		}
+
+		hit := false
+
+		switch b.Kind {
+		case BREAK_PC:
+			hit = uint32(cpu.pc) == b.Start && b.hit(cpu)
+		case BREAK_CONDITION:
+			held := b.held
+			b.held = b.Condition.Holds(cpu)
+			hit = b.held && !held && b.hit(cpu)
+		}
+
+		if hit && stop == nil {
+			stop = b
+		}
+	}
+
+	if stop == nil {
+		return nil
+	}
+
+	bs.resumed = true
+
+	return &BreakError{Breakpoint: stop, PC: cpu.pc}
+}
+
+// after returns the first write breakpoint hit by the instruction that
+// just ran.
+func (bs *Breakpoints) after(cpu *CPU) error {
+	if bs.pending == nil {
+		return nil
+	}
+
+	err := bs.pending
+	err.PC = cpu.pc
+	bs.pending = nil
+
+	return err
+}
+
+func (bs *Breakpoints) watch(addr uint32, old byte, value byte) {
+	for _, b := range bs.List {
+		if b.Disabled || b.Kind != BREAK_WRITE || addr < b.Start || addr > b.End {
+			continue
+		}
+
+		if b.hit(bs.cpu) && bs.pending == nil {
+			bs.pending = &BreakError{Breakpoint: b, Addr: addr, Old: old, Value: value}
+		}
+	}
+}
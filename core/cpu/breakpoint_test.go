@@ -0,0 +1,161 @@
+package cpu
+
+import (
+	"errors"
+	"testing"
+)
+
+// breakpointCPU runs LD V0, 0; LD I, 0x300; loop: ADD V0, 1; LD [I], V0;
+// JP loop.
+func breakpointCPU(specs ...string) (*CPU, error) {
+	cpu := NewCpu()
+	cpu.LoadROM([]byte{0x60, 0x00, 0xA3, 0x00, 0x70, 0x01, 0xF0, 0x55, 0x12, 0x04})
+	cpu.Breakpoints = &Breakpoints{}
+
+	for _, spec := range specs {
+		b, err := ParseBreakpoint(spec)
+
+		if err != nil {
+			return nil, err
+		}
+
+		cpu.Breakpoints.Add(b)
+	}
+
+	return &cpu, nil
+}
+
+// runUntilBreak runs at most n instructions and returns the breakpoint hit.
+func runUntilBreak(t *testing.T, cpu *CPU, n int) *BreakError {
+	for i := 0; i < n; i++ {
+		err := cpu.Run()
+
+		var b *BreakError
+
+		if errors.As(err, &b) {
+			return b
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return nil
+}
+
+func TestBreakpointPC(t *testing.T) {
+	cpu, _ := breakpointCPU("206")
+
+	for _, v0 := range []uint8{0x01, 0x02} {
+		b := runUntilBreak(t, cpu, 10)
+
+		if b == nil || b.PC != 0x206 {
+			t.Fatalf("break = %v; expected a break at 0x206", b)
+		}
+
+		if cpu.v[0] != v0 {
+			t.Errorf("cpu.v[0] = 0x%X; expected 0x%X", cpu.v[0], v0)
+		}
+	}
+}
+
+func TestBreakpointHitCount(t *testing.T) {
+	cpu, _ := breakpointCPU("206 hits 3")
+
+	b := runUntilBreak(t, cpu, 100)
+
+	if b == nil || cpu.v[0] != 0x03 {
+		t.Fatalf("break at V0 = 0x%X; expected 0x03", cpu.v[0])
+	}
+
+	if b.Breakpoint.Hits != 3 {
+		t.Errorf("Hits = %d; expected 3", b.Breakpoint.Hits)
+	}
+}
+
+func TestBreakpointWrite(t *testing.T) {
+	cpu, _ := breakpointCPU("write 300-30F if V0 == 2")
+
+	b := runUntilBreak(t, cpu, 100)
+
+	if b == nil {
+		t.Fatal("expected a break")
+	}
+
+	if b.Addr != 0x300 || b.Old != 0x01 || b.Value != 0x02 {
+		t.Errorf("write = %04X %02X -> %02X; expected 0300 01 -> 02", b.Addr, b.Old, b.Value)
+	}
+
+	if b.PC != 0x208 {
+		t.Errorf("b.PC = 0x%X; expected 0x208", b.PC)
+	}
+}
+
+func TestBreakpointCondition(t *testing.T) {
+	cpu, _ := breakpointCPU("if V0 >= 0x10 && I == 0x300")
+
+	b := runUntilBreak(t, cpu, 1000)
+
+	if b == nil || cpu.v[0] != 0x10 || cpu.pc != 0x206 {
+		t.Fatalf("break at V0 = 0x%X, pc = 0x%X; expected 0x10, 0x206", cpu.v[0], cpu.pc)
+	}
+
+	// The condition keeps holding, it only stops again once it became false
+	if b := runUntilBreak(t, cpu, 100); b != nil {
+		t.Errorf("unexpected break %v", b)
+	}
+}
+
+func TestParseBreakpoint(t *testing.T) {
+	invalid := []string{"", "write", "200-210", "write 300-200", "204 hits 0", "if V0 ==", "if VG == 1", "if (V0 == 1"}
+
+	for _, spec := range invalid {
+		if _, err := ParseBreakpoint(spec); err == nil {
+			t.Errorf("ParseBreakpoint(%q) expected an error", spec)
+		}
+	}
+
+	b, err := ParseBreakpoint("write 0x300-0x30F hits 2 if [I] != 0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Kind != BREAK_WRITE || b.Start != 0x300 || b.End != 0x30F || b.HitCount != 2 || b.Condition == nil {
+		t.Errorf("breakpoint = %+v", b)
+	}
+}
+
+func TestCondition(t *testing.T) {
+	cpu := NewCpu()
+	cpu.v[0xA] = 0x10
+	cpu.i = 0x301
+	cpu.delayTimer = 5
+	cpu.Keys[0xC] = 0x01
+	cpu.mmu.Write(0x301, 0x42)
+
+	conditions := map[string]bool{
+		"VA == 0x10 && I > 0x300": true,
+		"va == 16":                true,
+		"DT == 0 || ST > 0":       false,
+		"KC && !K0":               true,
+		"[I] == 0x42":             true,
+		"[I + 1] == 0x42":         false,
+		"VA - 1 == 15":            true,
+		"!(PC == 0x200)":          false,
+	}
+
+	for s, expected := range conditions {
+		c, err := ParseCondition(s)
+
+		if err != nil {
+			t.Errorf("ParseCondition(%q): %v", s, err)
+			continue
+		}
+
+		if c.Holds(&cpu) != expected {
+			t.Errorf("%q = %t; expected %t", s, !expected, expected)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package cpu
+
+import "fmt"
+
+const (
+	CALL_EVENT   = iota // 2NNN
+	RETURN_EVENT        // 00EE
+)
+
+// CallEvent is a subroutine call or return.
+type CallEvent struct {
+	Cycle uint64 // Instructions run before it
+	Kind  int
+	From  uint16 // Address of the instruction
+	To    uint16 // Subroutine called or address returned to
+	Depth int    // Calls on the stack after it
+}
+
+func (e CallEvent) String() string {
+	if e.Kind == RETURN_EVENT {
+		return fmt.Sprintf("%d %04X RET  %04X depth %d", e.Cycle, e.From, e.To, e.Depth)
+	}
+
+	return fmt.Sprintf("%d %04X CALL %04X depth %d", e.Cycle, e.From, e.To, e.Depth)
+}
+
+// CallLog keeps the last calls and returns of a CPU, set it as CPU.Calls.
+type CallLog struct {
+	events []CallEvent
+	next   int // Index of the oldest event once full
+}
+
+// NewCallLog keeps the last size events.
+func NewCallLog(size int) *CallLog {
+	return &CallLog{events: make([]CallEvent, 0, max(size, 1))}
+}
+
+func (l *CallLog) add(e CallEvent) {
+	if len(l.events) < cap(l.events) {
+		l.events = append(l.events, e)
+		return
+	}
+
+	l.events[l.next] = e
+	l.next = (l.next + 1) % len(l.events)
+}
+
+// Events returns a copy of the events kept, the oldest first.
+func (l *CallLog) Events() []CallEvent {
+	return append(append([]CallEvent(nil), l.events[l.next:]...), l.events[:l.next]...)
+}
+
+// Last returns the n most recent events, the oldest first.
+func (l *CallLog) Last(n int) []CallEvent {
+	events := l.Events()
+	return events[max(len(events)-n, 0):]
+}
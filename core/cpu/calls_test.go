@@ -0,0 +1,37 @@
+package cpu
+
+import "testing"
+
+func TestCallLog(t *testing.T) {
+	// loop: CALL sub; JP loop; sub: RET
+	cpu := NewCpu()
+	cpu.LoadROM([]byte{0x22, 0x04, 0x12, 0x00, 0x00, 0xEE})
+	cpu.Calls = NewCallLog(3)
+
+	for i := 0; i < 6; i++ {
+		if err := cpu.Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events := cpu.Calls.Events()
+	expected := []CallEvent{
+		{Cycle: 1, Kind: RETURN_EVENT, From: 0x204, To: 0x202, Depth: 0},
+		{Cycle: 3, Kind: CALL_EVENT, From: 0x200, To: 0x204, Depth: 1},
+		{Cycle: 4, Kind: RETURN_EVENT, From: 0x204, To: 0x202, Depth: 0},
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("Events() = %v; expected %v", events, expected)
+	}
+
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("Events()[%d] = %v; expected %v", i, events[i], expected[i])
+		}
+	}
+
+	if last := cpu.Calls.Last(1); len(last) != 1 || last[0] != expected[2] {
+		t.Errorf("Last(1) = %v; expected %v", last, expected[2:])
+	}
+}
@@ -0,0 +1,54 @@
+package cpu
+
+// CHIP-8E Instructions
+
+// chip8e executes the instructions added by CHIP-8E, the I/O port and
+// strobe instructions are not emulated.
+func (cpu *CPU) chip8e(oc *opcode) bool {
+	switch {
+	case oc.Instruction == 0x0000 && oc.NNN == 0x0ED:
+		// Stop
+		cpu.pc -= 2
+	case oc.Instruction == 0x0000 && oc.NNN == 0x151:
+		// Wait for the delay timer
+		if cpu.delayTimer > 0 {
+			cpu.pc -= 2
+		}
+	case oc.Instruction == 0x0000 && oc.NNN == 0x188:
+		cpu.pc += 2
+	case oc.Instruction == 0x5000 && oc.N == 0x1:
+		cpu.skp(cpu.v[oc.RegisterX] > cpu.v[oc.RegisterY])
+	case oc.Instruction == 0x5000 && oc.N == 0x2:
+		cpu.stmr(oc.RegisterX, oc.RegisterY)
+	case oc.Instruction == 0x5000 && oc.N == 0x3:
+		cpu.ldmr(oc.RegisterX, oc.RegisterY)
+	case oc.Instruction == 0xB000 && oc.RegisterX == 0xB:
+		// Branch back NN bytes from this instruction
+		cpu.pc -= 2 + uint16(oc.NN)
+	case oc.Instruction == 0xB000 && oc.RegisterX == 0xF:
+		// Branch forward NN bytes from this instruction
+		cpu.pc += uint16(oc.NN) - 2
+	case oc.Instruction == 0xF000 && oc.NN == 0x1B:
+		cpu.pc += uint16(cpu.v[oc.RegisterX])
+	default:
+		return false
+	}
+
+	return true
+}
+
+// stmr stores Vx to Vy at I and points I past them.
+func (cpu *CPU) stmr(x uint8, y uint8) {
+	for r := x; r <= y; r++ {
+		cpu.mmu.Write(cpu.i, cpu.v[r])
+		cpu.i++
+	}
+}
+
+// ldmr loads Vx to Vy from I and points I past them.
+func (cpu *CPU) ldmr(x uint8, y uint8) {
+	for r := x; r <= y; r++ {
+		cpu.v[r] = cpu.mmu.Load(uint32(cpu.i))
+		cpu.i++
+	}
+}
@@ -0,0 +1,52 @@
+package cpu
+
+// CHIP-8X Instructions
+
+// chip8x executes the instructions added by CHIP-8X for the VP-590 color
+// board and the VP-580 second keypad, BNNN is replaced by BXYN.
+func (cpu *CPU) chip8x(oc *opcode) bool {
+	switch {
+	case oc.Instruction == 0x0000 && oc.NNN == 0x2A0:
+		cpu.Zones.StepBackground()
+	case oc.Instruction == 0x5000 && oc.N == 0x1:
+		cpu.addn(oc.RegisterX, cpu.v[oc.RegisterY])
+	case oc.Instruction == 0xB000:
+		cpu.col(oc)
+	case oc.Instruction == 0xE000 && oc.NN == 0xF2:
+		cpu.skp(cpu.Keys2[cpu.v[oc.RegisterX]&0xF] == 0x01)
+	case oc.Instruction == 0xE000 && oc.NN == 0xF5:
+		cpu.skp(cpu.Keys2[cpu.v[oc.RegisterX]&0xF] == 0x00)
+	case oc.Instruction == 0xF000 && oc.NN == 0xF8:
+		cpu.Port = cpu.v[oc.RegisterX]
+	case oc.Instruction == 0xF000 && oc.NN == 0xFB:
+		// No device is wired to the input port
+		cpu.v[oc.RegisterX] = 0x00
+	default:
+		return false
+	}
+
+	return true
+}
+
+// addn adds each nibble of b to Vx without carrying between them.
+func (cpu *CPU) addn(vIndex uint8, b byte) {
+	vx := cpu.v[vIndex]
+
+	cpu.v[vIndex] = (vx+b)&0x0F | (vx&0xF0+b&0xF0)&0xF0
+}
+
+// col sets the foreground color to V(X+1). With N = 0 Vx and Vy hold the
+// position (low nibble) and size minus one (high nibble) of the area in
+// zones of 8x4 pixels, otherwise Vy is a pixel row and N rows are colored.
+func (cpu *CPU) col(oc *opcode) {
+	vx := int(cpu.v[oc.RegisterX])
+	vy := int(cpu.v[oc.RegisterY])
+	c := cpu.v[(oc.RegisterX+1)&0xF]
+
+	if oc.N == 0x0 {
+		cpu.Zones.SetZones((vy&0xF)*4, ((vy>>4)+1)*4, vx&0xF, (vx>>4)+1, c)
+		return
+	}
+
+	cpu.Zones.SetZones(vy, int(oc.N), vx&0xF, (vx>>4)+1, c)
+}
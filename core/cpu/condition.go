@@ -0,0 +1,280 @@
+package cpu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Condition is a boolean expression on the state of the machine, such as
+// "VA == 0x10 && I > 0x300". Operands are numbers (decimal or 0x
+// hexadecimal), the registers V0 to VF, I, PC and SP, the timers DT and
+// ST, K0 to KF (1 while the key is held) and [addr], the byte at addr.
+// Operators are ! + - == != < <= > >= && || and parentheses.
+type Condition struct {
+	Source string
+	eval   func(cpu *CPU) int64
+}
+
+func ParseCondition(s string) (*Condition, error) {
+	p := &conditionParser{source: s}
+
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+
+	eval, err := p.or()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("condition %q: unexpected %q", s, p.tokens[p.pos])
+	}
+
+	return &Condition{Source: s, eval: eval}, nil
+}
+
+// Holds reports whether the condition is true for cpu.
+func (c *Condition) Holds(cpu *CPU) bool {
+	return c.eval(cpu) != 0
+}
+
+// Value evaluates the condition as an expression, comparisons are 0 or 1.
+func (c *Condition) Value(cpu *CPU) int64 {
+	return c.eval(cpu)
+}
+
+func (c *Condition) String() string {
+	return c.Source
+}
+
+type conditionParser struct {
+	source string
+	tokens []string
+	pos    int
+}
+
+var conditionOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "(", ")", "[", "]"}
+
+func (p *conditionParser) tokenize() error {
+	s := p.source
+
+	for len(s) > 0 {
+		r := rune(s[0])
+
+		if unicode.IsSpace(r) {
+			s = s[1:]
+			continue
+		}
+
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			end := strings.IndexFunc(s, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+			})
+
+			if end < 0 {
+				end = len(s)
+			}
+
+			p.tokens = append(p.tokens, s[:end])
+			s = s[end:]
+			continue
+		}
+
+		found := false
+
+		for _, op := range conditionOperators {
+			if strings.HasPrefix(s, op) {
+				p.tokens = append(p.tokens, op)
+				s = s[len(op):]
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("condition %q: unexpected %q", p.source, s[:1])
+		}
+	}
+
+	return nil
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+func (p *conditionParser) next() string {
+	t := p.peek()
+	p.pos++
+
+	return t
+}
+
+type evaluator func(cpu *CPU) int64
+
+// binary parses the operands of the operators ops, from left to right.
+func (p *conditionParser) binary(ops map[string]func(a int64, b int64) int64, operand func() (evaluator, error)) (evaluator, error) {
+	left, err := operand()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := ops[p.peek()]
+
+		if !ok {
+			return left, nil
+		}
+
+		p.next()
+		right, err := operand()
+
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(cpu *CPU) int64 {
+			return op(l(cpu), right(cpu))
+		}
+	}
+}
+
+func boolean(b bool) int64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func (p *conditionParser) or() (evaluator, error) {
+	return p.binary(map[string]func(a int64, b int64) int64{
+		"||": func(a int64, b int64) int64 { return boolean(a != 0 || b != 0) },
+	}, p.and)
+}
+
+func (p *conditionParser) and() (evaluator, error) {
+	return p.binary(map[string]func(a int64, b int64) int64{
+		"&&": func(a int64, b int64) int64 { return boolean(a != 0 && b != 0) },
+	}, p.comparison)
+}
+
+func (p *conditionParser) comparison() (evaluator, error) {
+	return p.binary(map[string]func(a int64, b int64) int64{
+		"==": func(a int64, b int64) int64 { return boolean(a == b) },
+		"!=": func(a int64, b int64) int64 { return boolean(a != b) },
+		"<":  func(a int64, b int64) int64 { return boolean(a < b) },
+		"<=": func(a int64, b int64) int64 { return boolean(a <= b) },
+		">":  func(a int64, b int64) int64 { return boolean(a > b) },
+		">=": func(a int64, b int64) int64 { return boolean(a >= b) },
+	}, p.sum)
+}
+
+func (p *conditionParser) sum() (evaluator, error) {
+	return p.binary(map[string]func(a int64, b int64) int64{
+		"+": func(a int64, b int64) int64 { return a + b },
+		"-": func(a int64, b int64) int64 { return a - b },
+	}, p.unary)
+}
+
+func (p *conditionParser) unary() (evaluator, error) {
+	if p.peek() == "!" {
+		p.next()
+		operand, err := p.unary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return func(cpu *CPU) int64 { return boolean(operand(cpu) == 0) }, nil
+	}
+
+	return p.primary()
+}
+
+func (p *conditionParser) primary() (evaluator, error) {
+	t := p.next()
+
+	switch t {
+	case "":
+		return nil, fmt.Errorf("condition %q: unexpected end", p.source)
+	case "(", "[":
+		e, err := p.or()
+
+		if err != nil {
+			return nil, err
+		}
+
+		closing := map[string]string{"(": ")", "[": "]"}[t]
+
+		if p.next() != closing {
+			return nil, fmt.Errorf("condition %q: missing %q", p.source, closing)
+		}
+
+		if t == "(" {
+			return e, nil
+		}
+
+		return func(cpu *CPU) int64 {
+			addr := e(cpu)
+
+			if addr < 0 || addr >= int64(cpu.mmu.Size()) {
+				return 0
+			}
+
+			return int64(cpu.mmu.Peek(uint32(addr)))
+		}, nil
+	}
+
+	if n, err := strconv.ParseInt(t, 0, 64); err == nil {
+		return func(*CPU) int64 { return n }, nil
+	}
+
+	if e := operand(strings.ToUpper(t)); e != nil {
+		return e, nil
+	}
+
+	return nil, fmt.Errorf("condition %q: unknown operand %q", p.source, t)
+}
+
+// operand returns the evaluator of a register, timer or key name.
+func operand(name string) evaluator {
+	switch name {
+	case "I":
+		return func(cpu *CPU) int64 { return int64(cpu.addr()) }
+	case "PC":
+		return func(cpu *CPU) int64 { return int64(cpu.pc) }
+	case "SP":
+		return func(cpu *CPU) int64 { return int64(cpu.mmu.Stack.SP) }
+	case "DT":
+		return func(cpu *CPU) int64 { return int64(cpu.delayTimer) }
+	case "ST":
+		return func(cpu *CPU) int64 { return int64(cpu.SoundTimer) }
+	}
+
+	if len(name) != 2 || (name[0] != 'V' && name[0] != 'K') {
+		return nil
+	}
+
+	x, err := strconv.ParseUint(name[1:], 16, 4)
+
+	if err != nil {
+		return nil
+	}
+
+	if name[0] == 'K' {
+		return func(cpu *CPU) int64 { return boolean(cpu.Keys[x] != 0x00) }
+	}
+
+	return func(cpu *CPU) int64 { return int64(cpu.v[x]) }
+}
@@ -1,9 +1,10 @@
 package cpu
 
 import (
+	"errors"
+	"fmt"
+	"image/color"
 	"log"
-	"math/rand"
-	"os"
 	"time"
 
 	"github.com/gaoliveira21/chip8/core/font"
@@ -27,33 +28,136 @@ type CPU struct {
 	SoundTimer uint8
 
 	// Flags
-	Keys         [16]uint8
-	ResizeWindow bool
+	Keys [16]uint8
+
+	// FX0A
+	keyWait bool      // An FX0A is waiting for a key
+	keyDown bool      // The key has been pressed, waiting for its release
+	key     uint8     // Key pressed while waiting
+	keyHeld [16]uint8 // Keys seen by the previous cycle of the wait
 
 	// SCHIP Flags
-	RPL         [8]byte
+	RPL         [16]byte // 8 flags on the HP48, 16 on later SUPER-CHIP versions
 	SCHIP_HIRES bool
+
+	// CHIP-8X
+	Zones *graphics.ColorZones
+	Keys2 [16]uint8 // Second keypad
+	Port  uint8     // Output port, drives the VP-595 sound frequency
+
+	// MEGA-CHIP
+	MEGACHIP_MODE  bool
+	Color          *graphics.ColorGraphics
+	Sample         *Sample
+	iHigh          uint8 // High byte of the 24 bit I register
+	palette        [256]color.RGBA
+	spriteWidth    int
+	spriteHeight   int
+	blendMode      int
+	collisionColor uint8
+
+	Platform  Platform
+	Quirks    Quirks
+	extension extension
+
+	Trace       *Trace       // Logs every instruction when set
+	Breakpoints *Breakpoints // Stops Run with a *BreakError when set
+	Calls       *CallLog     // Logs subroutine calls and returns when set
+	Profile     *Profile     // Counts every instruction when set
+	cycle       uint64       // Instructions run so far
+	random      uint64       // State of the CXNN generator, see Seed
 }
 
 func NewCpu() CPU {
+	return NewCpuForPlatform(PLATFORM_SCHIP)
+}
+
+func NewCpuForPlatform(p Platform) CPU {
 	cpu := CPU{
-		pc:       0x200,
-		Graphics: graphics.NewGraphics(),
+		pc:        p.StartAddress(),
+		Graphics:  graphics.NewGraphics(),
+		Platform:  p,
+		Quirks:    LEGACY_QUIRKS,
+		extension: extensions[p],
+	}
+
+	switch p {
+	case PLATFORM_MEGACHIP:
+		cpu.mmu = memory.NewMMU(memory.MEGA_RAM_SIZE)
+		cpu.Color = graphics.NewColorGraphics()
+	case PLATFORM_CHIP8X:
+		cpu.Zones = graphics.NewColorZones()
+	case PLATFORM_CHIP10:
+		cpu.Graphics = graphics.NewHighResolutionGraphics()
 	}
 
 	cpu.loadFont()
+	cpu.Seed(uint64(time.Now().UnixNano()))
 
 	return cpu
 }
 
+// Clone returns a deep copy of the machine, used for save states.
+func (cpu *CPU) Clone() *CPU {
+	c := *cpu
+	c.mmu = cpu.mmu.Clone()
+	c.Graphics = cpu.Graphics.Clone()
+
+	if cpu.Zones != nil {
+		c.Zones = cpu.Zones.Clone()
+	}
+
+	if cpu.Color != nil {
+		c.Color = cpu.Color.Clone()
+	}
+
+	return &c
+}
+
 func (cpu *CPU) LoadROM(rom []byte) {
+	start := int(cpu.Platform.StartAddress())
+
 	for index, b := range rom {
-		cpu.mmu.Write(uint16(index)+0x200, b)
+		if index+start >= cpu.mmu.Size() {
+			log.Printf("ROM truncated to %d bytes", index)
+			return
+		}
+
+		cpu.mmu.Store(uint32(index+start), b)
 	}
 }
 
-func (cpu *CPU) Run() {
-	cpu.clock()
+// ErrExit is returned by Run when the ROM exits with the SUPER-CHIP 00FD.
+var ErrExit = errors.New("exit")
+
+// StackError is returned by Run when a 2NNN calls past the 16 levels of
+// the stack or a 00EE returns without a call.
+type StackError struct {
+	Overflow bool
+	PC       uint16
+}
+
+func (e *StackError) Error() string {
+	if e.Overflow {
+		return fmt.Sprintf("stack overflow at 0x%.4X", e.PC)
+	}
+
+	return fmt.Sprintf("stack underflow at 0x%.4X", e.PC)
+}
+
+// Run executes one instruction and updates the timers, it returns an
+// *UnsupportedOpcodeError when the ROM uses an instruction the platform
+// does not have, a *StackError when it overflows or underflows the stack,
+// ErrExit when it exits and a *BreakError when a breakpoint is hit, before
+// the instruction runs or after it writes to a watched address.
+func (cpu *CPU) Run() error {
+	if cpu.Breakpoints != nil {
+		if err := cpu.Breakpoints.before(cpu); err != nil {
+			return err
+		}
+	}
+
+	err := cpu.clock()
 
 	if cpu.delayTimer > 0 {
 		cpu.delayTimer--
@@ -62,6 +166,68 @@ func (cpu *CPU) Run() {
 	if cpu.SoundTimer > 0 {
 		cpu.SoundTimer--
 	}
+
+	if err == nil && cpu.Breakpoints != nil {
+		err = cpu.Breakpoints.after(cpu)
+	}
+
+	return err
+}
+
+// V returns the value of register Vx.
+func (cpu *CPU) V(x uint8) uint8 {
+	return cpu.v[x&0x0F]
+}
+
+func (cpu *CPU) PC() uint16 {
+	return cpu.pc
+}
+
+func (cpu *CPU) I() uint16 {
+	return cpu.i
+}
+
+// Addr returns I extended with the MEGA-CHIP high byte.
+func (cpu *CPU) Addr() uint32 {
+	return cpu.addr()
+}
+
+func (cpu *CPU) DelayTimer() uint8 {
+	return cpu.delayTimer
+}
+
+// SetV, SetPC, SetAddr and SetDelayTimer change the registers, for
+// debuggers.
+func (cpu *CPU) SetV(x uint8, value uint8) {
+	cpu.v[x&0x0F] = value
+}
+
+func (cpu *CPU) SetPC(pc uint16) {
+	cpu.pc = pc
+}
+
+func (cpu *CPU) SetAddr(addr uint32) {
+	cpu.i = uint16(addr)
+	cpu.iHigh = uint8(addr >> 16)
+}
+
+func (cpu *CPU) SetDelayTimer(value uint8) {
+	cpu.delayTimer = value
+}
+
+// Opcode returns the instruction at pc, the next one to run.
+func (cpu *CPU) Opcode() uint16 {
+	return uint16(cpu.mmu.Peek(uint32(cpu.pc)))<<8 | uint16(cpu.mmu.Peek(uint32(cpu.pc)+1))
+}
+
+// Memory returns the MMU, for debuggers.
+func (cpu *CPU) Memory() *memory.MMU {
+	return &cpu.mmu
+}
+
+// Stack returns a copy of the call stack.
+func (cpu *CPU) Stack() memory.Stack {
+	return cpu.mmu.Stack
 }
 
 func (cpu *CPU) loadFont() {
@@ -74,31 +240,65 @@ func (cpu *CPU) loadFont() {
 	}
 }
 
-func (cpu *CPU) drawBit(bit byte, y int, x int) {
+func (cpu *CPU) drawBit(bit byte, y int, x int) (collision bool) {
 	pixelOnDisplay := cpu.Graphics.GetPixel(int(y), int(x))
 
-	if bit == 0x01 && pixelOnDisplay == 0x01 {
-		cpu.v[0xF] = 0x01
+	cpu.Graphics.SetPixel(int(y), int(x), pixelOnDisplay^bit)
+
+	return bit == 0x01 && pixelOnDisplay == 0x01
+}
+
+// collisionFlag returns the VF value after drawing a sprite, SCHIP 1.1
+// reports how many rows collided or were clipped in high resolution.
+func (cpu *CPU) collisionFlag(rows int, clipped int) byte {
+	if cpu.Quirks.CollisionRows && cpu.SCHIP_HIRES {
+		return byte(rows + clipped)
 	}
 
-	cpu.Graphics.SetPixel(int(y), int(x), pixelOnDisplay^bit)
+	if rows > 0 {
+		return 0x01
+	}
+
+	return 0x00
 }
 
 func (cpu *CPU) decode(data uint16) (oc *opcode) {
 	return NewOpcode(data)
 }
 
-func (cpu *CPU) clock() {
-	data := cpu.mmu.Fetch(cpu.pc)
+func (cpu *CPU) clock() error {
+	cpu.mmu.PC = cpu.pc
+	data := cpu.mmu.Execute(cpu.pc)
+
+	if cpu.Trace != nil {
+		cpu.trace(data)
+	}
+
+	if cpu.Profile != nil {
+		cpu.Profile.add(cpu, data)
+	}
+
+	cpu.cycle++
 	cpu.pc += 2
 
 	opcode := cpu.decode(data)
 
+	if cpu.extension != nil && cpu.extension(cpu, opcode) {
+		return nil
+	}
+
 	switch opcode.Instruction {
 	case 0x0000:
-		if opcode.RegisterY == 0xC {
+		if !cpu.Platform.SCHIP() {
+			if opcode.RegisterX == 0x0 && (opcode.RegisterY == 0xC || opcode.RegisterY == 0xD || opcode.NNN >= 0x0FB) {
+				return cpu.unsupported(data)
+			}
+		} else if opcode.RegisterX == 0x0 && opcode.RegisterY == 0xC {
 			cpu.scd(opcode.N)
-			return
+			return nil
+		} else if opcode.RegisterX == 0x0 && opcode.RegisterY == 0xD {
+			cpu.scu(opcode.N)
+			return nil
 		}
 
 		switch opcode.NNN {
@@ -106,7 +306,7 @@ func (cpu *CPU) clock() {
 			cpu.cls()
 
 		case 0x0EE:
-			cpu.ret()
+			return cpu.ret()
 
 		case 0x0FE:
 			cpu.low()
@@ -121,7 +321,7 @@ func (cpu *CPU) clock() {
 			cpu.scl()
 
 		case 0x0FD:
-			cpu.ext()
+			return ErrExit
 
 		default:
 			cpu.jp(opcode.NNN, 0)
@@ -129,12 +329,16 @@ func (cpu *CPU) clock() {
 	case 0x1000:
 		cpu.jp(opcode.NNN, 0)
 	case 0x2000:
-		cpu.call(opcode.NNN)
+		return cpu.call(opcode.NNN)
 	case 0x3000:
 		cpu.skp(cpu.v[opcode.RegisterX] == opcode.NN)
 	case 0x4000:
 		cpu.skp(cpu.v[opcode.RegisterX] != opcode.NN)
 	case 0x5000:
+		if opcode.N != 0x0 {
+			return cpu.unsupported(data)
+		}
+
 		cpu.skp(cpu.v[opcode.RegisterX] == cpu.v[opcode.RegisterY])
 	case 0x6000:
 		cpu.ld(opcode.RegisterX, opcode.NN)
@@ -160,8 +364,14 @@ func (cpu *CPU) clock() {
 			cpu.sub(opcode.RegisterX, cpu.v[opcode.RegisterY], cpu.v[opcode.RegisterX])
 		case 0xE:
 			cpu.shl(opcode.RegisterX)
+		default:
+			return cpu.unsupported(data)
 		}
 	case 0x9000:
+		if opcode.N != 0x0 {
+			return cpu.unsupported(data)
+		}
+
 		cpu.skp(cpu.v[opcode.RegisterX] != cpu.v[opcode.RegisterY])
 	case 0xA000:
 		cpu.ldi(opcode.NNN)
@@ -170,8 +380,8 @@ func (cpu *CPU) clock() {
 	case 0xC000:
 		cpu.rnd(opcode.RegisterX, opcode.NN)
 	case 0xD000:
-		switch opcode.N {
-		case 0x0:
+		switch {
+		case opcode.N == 0x0 && cpu.Platform.SCHIP():
 			cpu.schip_drw(opcode)
 		default:
 			cpu.drw(opcode)
@@ -179,11 +389,19 @@ func (cpu *CPU) clock() {
 	case 0xE000:
 		switch opcode.NN {
 		case 0x9E:
-			cpu.skp(cpu.Keys[cpu.v[opcode.RegisterX]] == 0x01)
+			cpu.skp(cpu.Keys[cpu.v[opcode.RegisterX]&0xF] == 0x01)
 		case 0xA1:
-			cpu.skp(cpu.Keys[cpu.v[opcode.RegisterX]] == 0x00)
+			cpu.skp(cpu.Keys[cpu.v[opcode.RegisterX]&0xF] == 0x00)
+		default:
+			return cpu.unsupported(data)
 		}
 	case 0xF000:
+		if opcode.NN == 0x30 || opcode.NN == 0x75 || opcode.NN == 0x85 {
+			if !cpu.Platform.SCHIP() {
+				return cpu.unsupported(data)
+			}
+		}
+
 		switch opcode.NN {
 		case 0x07:
 			cpu.ld(opcode.RegisterX, cpu.delayTimer)
@@ -209,25 +427,55 @@ func (cpu *CPU) clock() {
 			cpu.srpl(opcode.RegisterX)
 		case 0x85:
 			cpu.lrpl(opcode.RegisterX)
+		default:
+			return cpu.unsupported(data)
 		}
 	}
+
+	return nil
 }
 
 func (cpu *CPU) cls() {
 	cpu.Graphics.Clear()
 }
 
-func (cpu *CPU) ret() {
+func (cpu *CPU) ret() error {
+	if cpu.mmu.Stack.Depth() == 0 {
+		return &StackError{PC: cpu.pc - 2}
+	}
+
+	from := cpu.pc - 2
 	cpu.pc = cpu.mmu.Stack.Pop()
+
+	if cpu.Calls != nil {
+		cpu.Calls.add(CallEvent{Cycle: cpu.cycle - 1, Kind: RETURN_EVENT, From: from, To: cpu.pc, Depth: cpu.mmu.Stack.Depth()})
+	}
+
+	return nil
 }
 
 func (cpu *CPU) jp(addr uint16, offset uint8) {
 	cpu.pc = addr + uint16(offset)
 }
 
-func (cpu *CPU) call(addr uint16) {
+func (cpu *CPU) call(addr uint16) error {
+	if cpu.mmu.Stack.Depth() == memory.STACK_SIZE {
+		return &StackError{Overflow: true, PC: cpu.pc - 2}
+	}
+
 	cpu.mmu.Stack.Push(cpu.pc)
+
+	if cpu.Calls != nil {
+		cpu.Calls.add(CallEvent{Cycle: cpu.cycle - 1, Kind: CALL_EVENT, From: cpu.pc - 2, To: addr, Depth: cpu.mmu.Stack.Depth()})
+	}
+
+	if cpu.Profile != nil {
+		cpu.Profile.call(addr, cpu.mmu.Stack.Depth())
+	}
+
 	cpu.pc = addr
+
+	return nil
 }
 
 func (cpu *CPU) skp(condition bool) {
@@ -267,16 +515,40 @@ func (cpu *CPU) sub(vIndex uint8, minuend byte, subtrahend byte) {
 
 func (cpu *CPU) ldi(value uint16) {
 	cpu.i = value
+	cpu.iHigh = 0x00
 }
 
+// ldk waits for a key to be pressed and released and stores it in Vx, a key
+// held when the wait starts only counts once released and pressed again.
 func (cpu *CPU) ldk(vIndex uint8) {
+	if !cpu.keyWait {
+		cpu.keyWait = true
+		cpu.keyDown = false
+		cpu.keyHeld = cpu.Keys
+	}
+
+	if cpu.keyDown && cpu.Keys[cpu.key] == 0x00 {
+		cpu.keyWait = false
+		cpu.v[vIndex] = cpu.key
+		return
+	}
+
 	for i, v := range cpu.Keys {
-		if v == 0x01 {
+		if cpu.keyDown || v == 0x00 || cpu.keyHeld[i] == 0x01 {
+			continue
+		}
+
+		if cpu.Quirks.KeyPressOnly {
+			cpu.keyWait = false
 			cpu.v[vIndex] = uint8(i)
 			return
 		}
+
+		cpu.keyDown = true
+		cpu.key = uint8(i)
 	}
 
+	cpu.keyHeld = cpu.Keys
 	cpu.pc -= 2
 }
 
@@ -319,10 +591,7 @@ func (cpu *CPU) shl(vIndex uint8) {
 }
 
 func (cpu *CPU) rnd(vIndex uint8, b byte) {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	randomByte := byte(r.Intn(256))
-
-	cpu.v[vIndex] = randomByte & b
+	cpu.v[vIndex] = cpu.randomByte() & b
 }
 
 func (cpu *CPU) adi(value uint16) {
@@ -341,23 +610,25 @@ func (cpu *CPU) stm(vIndex uint8) {
 
 func (cpu *CPU) ldm(vIndex uint8) {
 	for i := 0; uint8(i) <= vIndex; i++ {
-		cpu.v[i] = byte(cpu.mmu.Fetch(cpu.i+uint16(i)) >> 8)
+		cpu.v[i] = cpu.mmu.Load(uint32(cpu.i + uint16(i)))
 	}
 }
 
 func (cpu *CPU) drw(oc *opcode) {
 	x := cpu.v[oc.RegisterX] & (byte(cpu.Graphics.Width - 1))
 	y := cpu.v[oc.RegisterY] & (byte(cpu.Graphics.Height - 1))
-	cpu.v[0xF] = 0x00
+	rows := 0
+	clipped := 0
 
 	for i := 0; uint8(i) < oc.N; i++ {
 		addr := uint16(i) + cpu.i
-		pixels := byte(cpu.mmu.Fetch(addr) >> 8)
+		pixels := cpu.mmu.Load(uint32(addr))
 		xIndex := x
+		collision := false
 
 		for j := 0; j < 8; j++ {
 			bit := (pixels >> (7 - j)) & 0x01
-			cpu.drawBit(bit, int(y), int(xIndex))
+			collision = cpu.drawBit(bit, int(y), int(xIndex)) || collision
 
 			xIndex++
 
@@ -366,45 +637,60 @@ func (cpu *CPU) drw(oc *opcode) {
 			}
 		}
 
+		if collision {
+			rows++
+		}
+
 		y++
 
 		if int(y) >= cpu.Graphics.Height {
+			clipped = int(oc.N) - i - 1
 			break
 		}
 	}
+
+	cpu.v[0xF] = cpu.collisionFlag(rows, clipped)
 }
 
 // SCHIP Instructions
 
 func (cpu *CPU) high() {
 	cpu.Graphics.EnableHighResolutionMode()
-	cpu.ResizeWindow = true
 	cpu.SCHIP_HIRES = true
 }
 
 func (cpu *CPU) low() {
 	cpu.Graphics.DisableHighResolutionMode()
-	cpu.ResizeWindow = true
 	cpu.SCHIP_HIRES = false
 }
 
+// scrollAmount converts a distance in high resolution pixels to pixels of
+// the current resolution.
+func (cpu *CPU) scrollAmount(shift uint8) uint8 {
+	if cpu.Quirks.HalfScrollLores && !cpu.SCHIP_HIRES {
+		return shift / 2
+	}
+
+	return shift
+}
+
+func (cpu *CPU) scu(shift uint8) {
+	cpu.Graphics.ScrollUp(cpu.scrollAmount(shift))
+}
+
 func (cpu *CPU) scd(shift uint8) {
-	cpu.Graphics.ScrollDown(shift)
+	cpu.Graphics.ScrollDown(cpu.scrollAmount(shift))
 }
 
 func (cpu *CPU) scr() {
-	cpu.Graphics.ScrollRight()
+	cpu.Graphics.ScrollRight(cpu.scrollAmount(4))
 }
 
 func (cpu *CPU) scl() {
-	cpu.Graphics.ScrollLeft()
-}
-
-func (cpu *CPU) ext() {
-	log.Println("SCHIP [0x00FD] - Exit emulator")
-	os.Exit(0x0)
+	cpu.Graphics.ScrollLeft(cpu.scrollAmount(4))
 }
 
+// srpl saves V0 to VX in the flags.
 func (cpu *CPU) srpl(x uint8) {
 	for i := 0; i <= int(x); i++ {
 		cpu.RPL[i] = cpu.v[i]
@@ -420,7 +706,8 @@ func (cpu *CPU) lrpl(x uint8) {
 func (cpu *CPU) schip_drw(oc *opcode) {
 	x := cpu.v[oc.RegisterX] & (byte(cpu.Graphics.Width - 1))
 	y := cpu.v[oc.RegisterY] & (byte(cpu.Graphics.Height - 1))
-	cpu.v[0xF] = 0x00
+	rows := 0
+	clipped := 0
 
 	n := 16
 
@@ -429,17 +716,18 @@ func (cpu *CPU) schip_drw(oc *opcode) {
 		var sprite2 byte
 
 		if cpu.SCHIP_HIRES {
-			sprite1 = byte(cpu.mmu.Fetch((uint16(i)*2)+cpu.i) >> 8)
-			sprite2 = byte(cpu.mmu.Fetch((uint16(i)*2)+cpu.i+1) >> 8)
+			sprite1 = cpu.mmu.Load(uint32((uint16(i) * 2) + cpu.i))
+			sprite2 = cpu.mmu.Load(uint32((uint16(i) * 2) + cpu.i + 1))
 		} else {
-			sprite1 = byte(cpu.mmu.Fetch(uint16(i)+cpu.i) >> 8)
+			sprite1 = cpu.mmu.Load(uint32(uint16(i) + cpu.i))
 		}
 
 		xIndex := x
+		collision := false
 
 		for j := 0; j < 8; j++ {
 			bit := (sprite1 >> (7 - j)) & 0x01
-			cpu.drawBit(bit, int(y), int(xIndex))
+			collision = cpu.drawBit(bit, int(y), int(xIndex)) || collision
 
 			xIndex++
 
@@ -455,16 +743,23 @@ func (cpu *CPU) schip_drw(oc *opcode) {
 				}
 
 				bit := (sprite2 >> (7 - j)) & 0x01
-				cpu.drawBit(bit, int(y), int(xIndex))
+				collision = cpu.drawBit(bit, int(y), int(xIndex)) || collision
 
 				xIndex++
 			}
 		}
 
+		if collision {
+			rows++
+		}
+
 		y++
 
 		if int(y) >= cpu.Graphics.Height {
+			clipped = n - i - 1
 			break
 		}
 	}
+
+	cpu.v[0xF] = cpu.collisionFlag(rows, clipped)
 }
@@ -1,6 +1,7 @@
 package cpu
 
 import (
+	"errors"
 	"os"
 	"slices"
 	"testing"
@@ -596,15 +597,25 @@ func TestLDKWithNoKeyPressed(t *testing.T) {
 	}
 }
 
-func TestLDKWithKeyPressed(t *testing.T) {
+func TestLDKWithKeyPressedAndReleased(t *testing.T) {
 	cpu := NewCpu()
 	cpu.pc += 2
 
 	var vIndex uint8 = 0x1
 	var keyPressed uint8 = 0xF
 
+	cpu.ldk(vIndex)
+
 	cpu.Keys[keyPressed] = 0x1
+	cpu.pc += 2
+	cpu.ldk(vIndex)
+
+	if cpu.pc != 0x200 {
+		t.Errorf("cpu.pc = 0x%X; expected 0x200", cpu.pc)
+	}
 
+	cpu.Keys[keyPressed] = 0x0
+	cpu.pc += 2
 	cpu.ldk(vIndex)
 
 	if cpu.v[vIndex] != keyPressed {
@@ -612,10 +623,50 @@ func TestLDKWithKeyPressed(t *testing.T) {
 	}
 
 	if cpu.pc != 0x202 {
+		t.Errorf("cpu.pc = 0x%X; expected 0x202", cpu.pc)
+	}
+}
+
+func TestLDKWithKeyHeldBefore(t *testing.T) {
+	cpu := NewCpu()
+	cpu.pc += 2
+
+	var vIndex uint8 = 0x1
+
+	cpu.Keys[0x5] = 0x1
+	cpu.ldk(vIndex)
+
+	cpu.Keys[0x5] = 0x0
+	cpu.pc += 2
+	cpu.ldk(vIndex)
+
+	if cpu.pc != 0x200 {
 		t.Errorf("cpu.pc = 0x%X; expected 0x200", cpu.pc)
 	}
 }
 
+func TestLDKPressOnly(t *testing.T) {
+	cpu := NewCpu()
+	cpu.Quirks.KeyPressOnly = true
+	cpu.pc += 2
+
+	var vIndex uint8 = 0x1
+
+	cpu.ldk(vIndex)
+
+	cpu.Keys[0xA] = 0x1
+	cpu.pc += 2
+	cpu.ldk(vIndex)
+
+	if cpu.v[vIndex] != 0xA {
+		t.Errorf("cpu.v[%d] = 0x%X; expected 0xA", vIndex, cpu.v[vIndex])
+	}
+
+	if cpu.pc != 0x202 {
+		t.Errorf("cpu.pc = 0x%X; expected 0x202", cpu.pc)
+	}
+}
+
 func TestADIWithoutOverflow(t *testing.T) {
 	cpu := NewCpu()
 
@@ -707,3 +758,137 @@ func TestDRWNoWrapAndNoCollision(t *testing.T) {
 		t.Errorf("cpu.Graphics.GetPixel(1,4) = 0x%X; expected 0x01", cpu.Graphics.GetPixel(1, 4))
 	}
 }
+
+func TestClone(t *testing.T) {
+	cpu := NewCpu()
+	cpu.LoadROM([]byte{0x60, 0x01})
+	cpu.Graphics.SetPixel(0, 0, 0x01)
+	cpu.v[0x3] = 0x33
+
+	clone := cpu.Clone()
+
+	cpu.Run()
+	cpu.Graphics.SetPixel(0, 0, 0x00)
+	cpu.mmu.Write(0x200, 0xFF)
+
+	if clone.pc != 0x200 {
+		t.Errorf("clone.pc = 0x%X; expected 0x200", clone.pc)
+	}
+
+	if clone.v[0x3] != 0x33 {
+		t.Errorf("clone.v[3] = 0x%X; expected 0x33", clone.v[0x3])
+	}
+
+	if b := clone.mmu.Fetch(0x200); b != 0x6001 {
+		t.Errorf("clone.mmu.Fetch(0x200) = 0x%X; expected 0x6001", b)
+	}
+
+	if p := clone.Graphics.GetPixel(0, 0); p != 0x01 {
+		t.Errorf("clone.Graphics.GetPixel(0, 0) = 0x%X; expected 0x1", p)
+	}
+}
+
+func TestRNDSeed(t *testing.T) {
+	run := func(cpu *CPU) [8]byte {
+		var values [8]byte
+
+		for i := range values {
+			cpu.pc = 0x200
+			cpu.clock()
+			values[i] = cpu.v[0x1]
+		}
+
+		return values
+	}
+
+	a, b := NewCpu(), NewCpu()
+
+	for _, cpu := range []*CPU{&a, &b} {
+		cpu.mmu.Write(0x200, 0xC1)
+		cpu.mmu.Write(0x201, 0xFF)
+		cpu.Seed(42)
+	}
+
+	first := run(&a)
+
+	if second := run(&b); first != second {
+		t.Errorf("random bytes = %v; expected %v with the same seed", second, first)
+	}
+
+	if first == [8]byte{} {
+		t.Errorf("random bytes = %v; expected random values", first)
+	}
+
+	clone := a.Clone()
+
+	if x, y := run(&a), run(clone); x != y {
+		t.Errorf("random bytes of the clone = %v; expected %v", y, x)
+	}
+}
+
+func TestCALLOverflow(t *testing.T) {
+	cpu := NewCpu()
+
+	cpu.mmu.Write(0x200, 0x22)
+	cpu.mmu.Write(0x201, 0x00)
+
+	for i := 0; i < 16; i++ {
+		if err := cpu.Run(); err != nil {
+			t.Fatalf("call %d: cpu.Run() = %v; expected nil", i, err)
+		}
+	}
+
+	var stack *StackError
+
+	if err := cpu.Run(); !errors.As(err, &stack) || !stack.Overflow || stack.PC != 0x200 {
+		t.Errorf("cpu.Run() = %v; expected a stack overflow at 0x200", err)
+	}
+}
+
+func TestRETUnderflow(t *testing.T) {
+	cpu := NewCpu()
+
+	cpu.mmu.Write(0x200, 0x00)
+	cpu.mmu.Write(0x201, 0xEE)
+
+	var stack *StackError
+
+	if err := cpu.Run(); !errors.As(err, &stack) || stack.Overflow {
+		t.Errorf("cpu.Run() = %v; expected a stack underflow", err)
+	}
+}
+
+func TestEXIT(t *testing.T) {
+	cpu := NewCpu()
+
+	cpu.mmu.Write(0x200, 0x00)
+	cpu.mmu.Write(0x201, 0xFD)
+
+	if err := cpu.Run(); err != ErrExit {
+		t.Errorf("cpu.Run() = %v; expected ErrExit", err)
+	}
+}
+
+func TestRPLSavesAllRegisters(t *testing.T) {
+	cpu := NewCpuForPlatform(PLATFORM_SCHIP)
+
+	for x := uint8(0); x < 16; x++ {
+		cpu.v[x] = x + 1
+	}
+
+	// LD R, VF; LD VF, R after clearing the registers
+	cpu.mmu.Write(0x200, 0xFF)
+	cpu.mmu.Write(0x201, 0x75)
+	cpu.mmu.Write(0x202, 0xFF)
+	cpu.mmu.Write(0x203, 0x85)
+
+	cpu.clock()
+	cpu.v = [16]uint8{}
+	cpu.clock()
+
+	for x := uint8(0); x < 16; x++ {
+		if cpu.v[x] != x+1 {
+			t.Errorf("cpu.v[0x%X] = 0x%X; expected 0x%X", x, cpu.v[x], x+1)
+		}
+	}
+}
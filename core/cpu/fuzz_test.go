@@ -0,0 +1,66 @@
+package cpu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// FUZZ_STEPS bounds the instructions FuzzRun runs, most random ROMs loop.
+const FUZZ_STEPS = 2000
+
+// FuzzRun runs arbitrary ROMs on every platform, Run may return an error
+// but must not panic.
+//
+//	go test ./core/cpu -fuzz FuzzRun
+func FuzzRun(f *testing.F) {
+	roms, err := filepath.Glob("../../cli/roms/*.ch8")
+
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, path := range roms {
+		rom, err := os.ReadFile(path)
+
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(uint8(4), rom)
+	}
+
+	f.Add(uint8(0), []byte{0x00, 0xEE})                         // Returns with an empty stack
+	f.Add(uint8(0), []byte{0x22, 0x00})                         // Calls itself until the stack overflows
+	f.Add(uint8(0), []byte{0x1F, 0xFF})                         // Runs the last byte of the memory
+	f.Add(uint8(0), []byte{0x6F, 0xFF, 0xEF, 0x9E})             // Tests key 0xFF
+	f.Add(uint8(4), []byte{0x00, 0xFD})                         // Exits
+	f.Add(uint8(4), []byte{0xFF, 0x75})                         // Saves 16 flags
+	f.Add(uint8(5), []byte{0x01, 0xFF, 0xFF, 0xFF, 0x06, 0x00}) // Plays a sample at the end of the memory
+
+	f.Fuzz(func(t *testing.T, platform uint8, rom []byte) {
+		cpu := NewCpuForPlatform(Platforms[int(platform)%len(Platforms)])
+		cpu.Seed(0)
+		cpu.LoadROM(rom)
+
+		for i := 0; i < FUZZ_STEPS; i++ {
+			// Every key is pressed and released in turn, for FX0A
+			cpu.Keys[i/64%16] = uint8(i / 32 % 2)
+
+			err := cpu.Run()
+
+			var unsupported *UnsupportedOpcodeError
+			var stack *StackError
+
+			switch {
+			case err == nil:
+				continue
+			case errors.As(err, &unsupported), errors.As(err, &stack), errors.Is(err, ErrExit):
+				return
+			default:
+				t.Fatalf("cpu.Run() = %v; expected an unsupported opcode, a stack error or an exit", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,197 @@
+package cpu
+
+import (
+	"image/color"
+
+	"github.com/gaoliveira21/chip8/core/graphics"
+)
+
+// Sample is a digitised sound started by MEGA-CHIP 060N.
+type Sample struct {
+	Rate int
+	Data []byte // Unsigned 8 bit mono samples
+	Loop bool
+}
+
+// MEGA-CHIP Instructions
+
+// megachip executes the instructions added or changed by MEGA-CHIP and
+// reports whether the opcode was one of them.
+func (cpu *CPU) megachip(oc *opcode) bool {
+	if oc.Instruction == 0xD000 && cpu.MEGACHIP_MODE {
+		cpu.mega_drw(oc)
+		return true
+	}
+
+	if oc.Instruction != 0x0000 {
+		return false
+	}
+
+	switch oc.NNN {
+	case 0x010:
+		cpu.megaoff()
+		return true
+	case 0x011:
+		cpu.megaon()
+		return true
+	case 0x0E0:
+		if cpu.MEGACHIP_MODE {
+			cpu.Color.Flip()
+			return true
+		}
+
+		return false
+	case 0x700:
+		cpu.Sample = nil
+		return true
+	}
+
+	if oc.RegisterY == 0xB && oc.RegisterX == 0x0 {
+		cpu.Color.ScrollUp(oc.N)
+		return true
+	}
+
+	switch oc.RegisterX {
+	case 0x1:
+		cpu.ldhi(oc.NN)
+	case 0x2:
+		cpu.ldpal(oc.NN)
+	case 0x3:
+		cpu.spriteWidth = spriteSize(oc.NN)
+	case 0x4:
+		cpu.spriteHeight = spriteSize(oc.NN)
+	case 0x5:
+		cpu.Color.Alpha = oc.NN
+	case 0x6:
+		cpu.digisnd(oc.N == 0x0)
+	case 0x8:
+		cpu.blendMode = int(oc.N)
+	case 0x9:
+		cpu.collisionColor = oc.NN
+	default:
+		return false
+	}
+
+	return true
+}
+
+func spriteSize(nn uint8) int {
+	if nn == 0 {
+		return 0x100
+	}
+
+	return int(nn)
+}
+
+// addr returns I extended by the high byte set with 01NN NNNN.
+func (cpu *CPU) addr() uint32 {
+	return uint32(cpu.iHigh)<<16 | uint32(cpu.i)
+}
+
+func (cpu *CPU) megaon() {
+	cpu.MEGACHIP_MODE = true
+	cpu.Color.Clear()
+}
+
+func (cpu *CPU) megaoff() {
+	cpu.MEGACHIP_MODE = false
+	cpu.Graphics.Clear()
+}
+
+// ldhi loads the 24 bit I register, the low 16 bits are the next word.
+func (cpu *CPU) ldhi(nn uint8) {
+	cpu.iHigh = nn
+	cpu.i = cpu.mmu.Fetch(cpu.pc)
+	cpu.pc += 2
+}
+
+// ldpal loads n ARGB colors from I into the palette starting at index 1.
+func (cpu *CPU) ldpal(n uint8) {
+	addr := cpu.addr()
+
+	for i := 1; i <= int(n); i++ {
+		cpu.palette[i] = color.RGBA{
+			A: cpu.mmu.Load(addr),
+			R: cpu.mmu.Load(addr + 1),
+			G: cpu.mmu.Load(addr + 2),
+			B: cpu.mmu.Load(addr + 3),
+		}
+
+		addr += 4
+	}
+}
+
+// digisnd plays the sample at I, it starts with a 6 bytes header holding
+// the sample rate (16 bits) and the length (24 bits).
+func (cpu *CPU) digisnd(loop bool) {
+	addr := cpu.addr()
+
+	rate := int(cpu.mmu.Load(addr))<<8 | int(cpu.mmu.Load(addr+1))
+	length := int(cpu.mmu.Load(addr+2))<<16 | int(cpu.mmu.Load(addr+3))<<8 | int(cpu.mmu.Load(addr+4))
+	length = max(min(length, cpu.mmu.Size()-int(addr)-6), 0)
+
+	data := make([]byte, length)
+
+	for i := range data {
+		data[i] = cpu.mmu.Load(addr + 6 + uint32(i))
+	}
+
+	cpu.Sample = &Sample{
+		Rate: rate,
+		Data: data,
+		Loop: loop,
+	}
+}
+
+// mega_drw draws a sprite of palette indexes sized by 03NN and 04NN, index
+// 0 is transparent, font sprites keep their 1 bit format.
+func (cpu *CPU) mega_drw(oc *opcode) {
+	x := int(cpu.v[oc.RegisterX])
+	y := int(cpu.v[oc.RegisterY])
+	addr := cpu.addr()
+	cpu.v[0xF] = 0x00
+
+	if addr < 0x200 {
+		cpu.mega_drw_font(x, y, oc.N)
+		return
+	}
+
+	for row := 0; row < cpu.spriteHeight; row++ {
+		for col := 0; col < cpu.spriteWidth; col++ {
+			index := cpu.mmu.Load(addr)
+			addr++
+
+			px := x + col
+			py := y + row
+
+			if index == 0x00 || px >= cpu.Color.Width || py >= cpu.Color.Height {
+				continue
+			}
+
+			if cpu.Color.GetIndex(py, px) == cpu.collisionColor {
+				cpu.v[0xF] = 0x01
+			}
+
+			cpu.Color.SetPixel(py, px, cpu.palette[index], index, cpu.blendMode)
+		}
+	}
+}
+
+func (cpu *CPU) mega_drw_font(x int, y int, n uint8) {
+	white := color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+
+	for row := 0; row < int(n); row++ {
+		pixels := cpu.mmu.Load(cpu.addr() + uint32(row))
+
+		for col := 0; col < 8; col++ {
+			px := x + col
+			py := y + row
+
+			if (pixels>>(7-col))&0x01 == 0x00 || px >= cpu.Color.Width || py >= cpu.Color.Height {
+				continue
+			}
+
+			cpu.Color.SetPixel(py, px, white, 0xFF, graphics.BLEND_NORMAL)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package cpu
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestMegaLDHI(t *testing.T) {
+	cpu := NewCpuForPlatform(PLATFORM_MEGACHIP)
+
+	cpu.mmu.Write(0x200, 0x01)
+	cpu.mmu.Write(0x201, 0x12)
+	cpu.mmu.Write(0x202, 0x34)
+	cpu.mmu.Write(0x203, 0x56)
+
+	cpu.clock()
+
+	if cpu.addr() != 0x123456 {
+		t.Errorf("cpu.addr() = 0x%X; expected 0x123456", cpu.addr())
+	}
+
+	if cpu.pc != 0x204 {
+		t.Errorf("cpu.pc = 0x%X; expected 0x204", cpu.pc)
+	}
+}
+
+func TestMegaDRW(t *testing.T) {
+	cpu := NewCpuForPlatform(PLATFORM_MEGACHIP)
+	cpu.megaon()
+
+	// Palette with a single opaque red color at I = 0x10000
+	cpu.mmu.Store(0x10000, 0xFF)
+	cpu.mmu.Store(0x10001, 0xFF)
+	cpu.ldhi(0x01)
+	cpu.i = 0x0000
+	cpu.ldpal(1)
+
+	// 2x1 sprite, the second pixel is transparent
+	cpu.mmu.Store(0x20000, 0x01)
+	cpu.mmu.Store(0x20001, 0x00)
+	cpu.iHigh = 0x02
+	cpu.spriteWidth = 2
+	cpu.spriteHeight = 1
+	cpu.collisionColor = 0x01
+
+	cpu.v[0x0] = 10
+	cpu.v[0x1] = 20
+	cpu.mega_drw(NewOpcode(0xD011))
+
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+
+	if cpu.Color.GetPixel(20, 10) != red {
+		t.Errorf("cpu.Color.GetPixel(20, 10) = %v; expected %v", cpu.Color.GetPixel(20, 10), red)
+	}
+
+	if cpu.Color.GetIndex(20, 11) != 0x00 {
+		t.Errorf("cpu.Color.GetIndex(20, 11) = 0x%X; expected 0x00", cpu.Color.GetIndex(20, 11))
+	}
+
+	if cpu.v[0xF] != 0x00 {
+		t.Errorf("cpu.v[0xF] = 0x%X; expected 0x00", cpu.v[0xF])
+	}
+
+	cpu.mega_drw(NewOpcode(0xD011))
+
+	if cpu.v[0xF] != 0x01 {
+		t.Errorf("cpu.v[0xF] = 0x%X; expected 0x01", cpu.v[0xF])
+	}
+
+	cpu.pc = 0x200
+	cpu.mmu.Write(0x200, 0x00)
+	cpu.mmu.Write(0x201, 0xE0)
+	cpu.clock()
+
+	if cpu.Color.Image().RGBAAt(10, 20) != red {
+		t.Errorf("presented pixel = %v; expected %v", cpu.Color.Image().RGBAAt(10, 20), red)
+	}
+
+	if cpu.Color.GetIndex(20, 10) != 0x00 {
+		t.Error("back buffer expected to be cleared after 00E0")
+	}
+}
+
+func TestMegaDigitisedSound(t *testing.T) {
+	cpu := NewCpuForPlatform(PLATFORM_MEGACHIP)
+
+	header := []byte{0x1F, 0x40, 0x00, 0x00, 0x03, 0x00, 0x80, 0xFF, 0x00}
+
+	for i, b := range header {
+		cpu.mmu.Store(0x30000+uint32(i), b)
+	}
+
+	cpu.iHigh = 0x03
+	cpu.i = 0x0000
+
+	cpu.mmu.Write(0x200, 0x06)
+	cpu.mmu.Write(0x201, 0x01)
+	cpu.mmu.Write(0x202, 0x07)
+	cpu.mmu.Write(0x203, 0x00)
+
+	cpu.clock()
+
+	if cpu.Sample == nil {
+		t.Fatal("cpu.Sample = nil; expected a sample")
+	}
+
+	if cpu.Sample.Rate != 8000 || len(cpu.Sample.Data) != 3 || cpu.Sample.Loop {
+		t.Errorf("cpu.Sample = %+v; expected 3 samples at 8000 Hz played once", cpu.Sample)
+	}
+
+	cpu.clock()
+
+	if cpu.Sample != nil {
+		t.Error("cpu.Sample expected to be nil after 0700")
+	}
+}
+
+func TestDIGISNDHeaderAtEndOfMemory(t *testing.T) {
+	cpu := NewCpuForPlatform(PLATFORM_MEGACHIP)
+	addr := uint32(cpu.mmu.Size() - 3)
+
+	// The length field would reach past the end of memory
+	cpu.mmu.Store(addr+2, 0xFF)
+	cpu.iHigh = uint8(addr >> 16)
+	cpu.i = uint16(addr)
+
+	cpu.mmu.Write(0x200, 0x06)
+	cpu.mmu.Write(0x201, 0x01)
+
+	cpu.clock()
+
+	if cpu.Sample == nil || len(cpu.Sample.Data) != 0 {
+		t.Errorf("cpu.Sample = %+v; expected an empty sample", cpu.Sample)
+	}
+}
+
+func TestMegachipOpcodesIgnoredOnSCHIP(t *testing.T) {
+	cpu := NewCpu()
+
+	cpu.mmu.Write(0x200, 0x00)
+	cpu.mmu.Write(0x201, 0x11)
+
+	cpu.clock()
+
+	if cpu.MEGACHIP_MODE {
+		t.Error("cpu.MEGACHIP_MODE = true; expected false on SCHIP")
+	}
+}
@@ -0,0 +1,76 @@
+package cpu
+
+import "fmt"
+
+type Platform string
+
+const (
+	PLATFORM_CHIP8    Platform = "chip8"
+	PLATFORM_CHIP8E   Platform = "chip8e"
+	PLATFORM_CHIP8X   Platform = "chip8x"
+	PLATFORM_CHIP10   Platform = "chip10"
+	PLATFORM_SCHIP    Platform = "schip"
+	PLATFORM_MEGACHIP Platform = "megachip"
+)
+
+var Platforms = []Platform{
+	PLATFORM_CHIP8,
+	PLATFORM_CHIP8E,
+	PLATFORM_CHIP8X,
+	PLATFORM_CHIP10,
+	PLATFORM_SCHIP,
+	PLATFORM_MEGACHIP,
+}
+
+// extension runs before the CHIP-8 dispatch of clock and reports whether it
+// executed the opcode.
+type extension func(cpu *CPU, oc *opcode) bool
+
+var extensions = map[Platform]extension{
+	PLATFORM_CHIP8E:   (*CPU).chip8e,
+	PLATFORM_CHIP8X:   (*CPU).chip8x,
+	PLATFORM_MEGACHIP: (*CPU).megachip,
+}
+
+func ParsePlatform(name string) (Platform, error) {
+	for _, p := range Platforms {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown platform %q", name)
+}
+
+// SCHIP reports whether the platform runs the SUPER-CHIP instructions.
+func (p Platform) SCHIP() bool {
+	return p == PLATFORM_SCHIP || p == PLATFORM_MEGACHIP
+}
+
+// StartAddress is where ROMs are loaded and executed from, the CHIP-8X
+// interpreter takes more memory than the original one.
+func (p Platform) StartAddress() uint16 {
+	if p == PLATFORM_CHIP8X {
+		return 0x300
+	}
+
+	return 0x200
+}
+
+type UnsupportedOpcodeError struct {
+	Platform Platform
+	Opcode   uint16
+	PC       uint16
+}
+
+func (e *UnsupportedOpcodeError) Error() string {
+	return fmt.Sprintf("opcode 0x%.4X at 0x%.4X is not supported by platform %s", e.Opcode, e.PC, e.Platform)
+}
+
+func (cpu *CPU) unsupported(data uint16) error {
+	return &UnsupportedOpcodeError{
+		Platform: cpu.Platform,
+		Opcode:   data,
+		PC:       cpu.pc - 2,
+	}
+}
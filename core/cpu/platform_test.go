@@ -0,0 +1,182 @@
+package cpu
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gaoliveira21/chip8/core/graphics"
+)
+
+func TestUnsupportedOpcode(t *testing.T) {
+	cpu := NewCpuForPlatform(PLATFORM_CHIP8)
+
+	cpu.mmu.Write(0x200, 0x00)
+	cpu.mmu.Write(0x201, 0xFF)
+
+	err := cpu.Run()
+
+	var unsupported *UnsupportedOpcodeError
+
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("cpu.Run() = %v; expected *UnsupportedOpcodeError", err)
+	}
+
+	if unsupported.Opcode != 0x00FF || unsupported.PC != 0x200 {
+		t.Errorf("error = %+v; expected opcode 0x00FF at 0x200", unsupported)
+	}
+
+	if cpu.SCHIP_HIRES {
+		t.Error("cpu.SCHIP_HIRES = true; expected the opcode to be rejected")
+	}
+}
+
+func TestUnknownOpcode(t *testing.T) {
+	cpu := NewCpu()
+
+	cpu.mmu.Write(0x200, 0xE1)
+	cpu.mmu.Write(0x201, 0x00)
+
+	if err := cpu.Run(); err == nil {
+		t.Error("cpu.Run() = nil; expected an error for 0xE100")
+	}
+}
+
+func TestParsePlatform(t *testing.T) {
+	for _, p := range Platforms {
+		parsed, err := ParsePlatform(string(p))
+
+		if err != nil || parsed != p {
+			t.Errorf("ParsePlatform(%q) = %q, %v", p, parsed, err)
+		}
+	}
+
+	if _, err := ParsePlatform("xochip"); err == nil {
+		t.Error("ParsePlatform(\"xochip\") expected an error")
+	}
+}
+
+func TestCHIP8XStartAddress(t *testing.T) {
+	cpu := NewCpuForPlatform(PLATFORM_CHIP8X)
+	cpu.LoadROM([]byte{0x12, 0x34})
+
+	if cpu.pc != 0x300 {
+		t.Errorf("cpu.pc = 0x%X; expected 0x300", cpu.pc)
+	}
+
+	if cpu.mmu.Fetch(0x300) != 0x1234 {
+		t.Errorf("cpu.mmu.Fetch(0x300) = 0x%X; expected 0x1234", cpu.mmu.Fetch(0x300))
+	}
+}
+
+func TestCHIP8XColors(t *testing.T) {
+	cpu := NewCpuForPlatform(PLATFORM_CHIP8X)
+
+	// Zone 2, one zone wide, rows 4 to 7 in violet
+	cpu.v[0x4] = 0x02
+	cpu.v[0x5] = 0x03
+	cpu.v[0x6] = 0x04
+
+	cpu.mmu.Write(0x300, 0x02)
+	cpu.mmu.Write(0x301, 0xA0)
+	cpu.mmu.Write(0x302, 0xB4)
+	cpu.mmu.Write(0x303, 0x64)
+
+	cpu.clock()
+
+	if cpu.Zones.Background() != graphics.CHIP8X_COLORS[0] {
+		t.Errorf("background = %v; expected black", cpu.Zones.Background())
+	}
+
+	cpu.clock()
+
+	violet := graphics.CHIP8X_COLORS[3]
+
+	if cpu.Zones.Foreground(5, 16) != violet {
+		t.Errorf("Foreground(5, 16) = %v; expected %v", cpu.Zones.Foreground(5, 16), violet)
+	}
+
+	if cpu.Zones.Foreground(5, 24) == violet {
+		t.Error("Foreground(5, 24) expected to keep its color")
+	}
+}
+
+func TestCHIP8XSecondKeypad(t *testing.T) {
+	cpu := NewCpuForPlatform(PLATFORM_CHIP8X)
+
+	cpu.v[0x1] = 0x7
+	cpu.Keys2[0x7] = 0x01
+
+	cpu.mmu.Write(0x300, 0xE1)
+	cpu.mmu.Write(0x301, 0xF2)
+
+	cpu.clock()
+
+	if cpu.pc != 0x304 {
+		t.Errorf("cpu.pc = 0x%X; expected 0x304", cpu.pc)
+	}
+}
+
+func TestCHIP8XNibbleAdd(t *testing.T) {
+	cpu := NewCpuForPlatform(PLATFORM_CHIP8X)
+
+	cpu.v[0x1] = 0x9C
+	cpu.v[0x2] = 0x85
+
+	cpu.mmu.Write(0x300, 0x51)
+	cpu.mmu.Write(0x301, 0x21)
+
+	cpu.clock()
+
+	if cpu.v[0x1] != 0x11 {
+		t.Errorf("cpu.v[0x1] = 0x%X; expected 0x11", cpu.v[0x1])
+	}
+}
+
+func TestCHIP8EInstructions(t *testing.T) {
+	cpu := NewCpuForPlatform(PLATFORM_CHIP8E)
+
+	cpu.v[0x1] = 0x05
+	cpu.v[0x2] = 0x03
+	cpu.i = 0x400
+
+	// 5121 skips, 5122 stores V1 and V2, BB04 jumps back to 0x200
+	cpu.mmu.Write(0x200, 0x51)
+	cpu.mmu.Write(0x201, 0x21)
+	cpu.mmu.Write(0x204, 0x51)
+	cpu.mmu.Write(0x205, 0x22)
+	cpu.mmu.Write(0x206, 0xBB)
+	cpu.mmu.Write(0x207, 0x06)
+
+	cpu.clock()
+
+	if cpu.pc != 0x204 {
+		t.Errorf("cpu.pc = 0x%X; expected 0x204", cpu.pc)
+	}
+
+	cpu.clock()
+
+	if cpu.mmu.Fetch(0x400) != 0x0503 || cpu.i != 0x402 {
+		t.Errorf("memory = 0x%X, cpu.i = 0x%X; expected 0x0503 and 0x402", cpu.mmu.Fetch(0x400), cpu.i)
+	}
+
+	cpu.clock()
+
+	if cpu.pc != 0x200 {
+		t.Errorf("cpu.pc = 0x%X; expected 0x200", cpu.pc)
+	}
+}
+
+func TestCHIP10Display(t *testing.T) {
+	cpu := NewCpuForPlatform(PLATFORM_CHIP10)
+
+	if cpu.Graphics.Width != 0x80 || cpu.Graphics.Height != 0x40 {
+		t.Errorf("display = %dx%d; expected 128x64", cpu.Graphics.Width, cpu.Graphics.Height)
+	}
+
+	cpu.mmu.Write(0x200, 0x00)
+	cpu.mmu.Write(0x201, 0xFE)
+
+	if err := cpu.clock(); err == nil {
+		t.Error("cpu.clock() = nil; expected 00FE to be rejected")
+	}
+}
@@ -0,0 +1,159 @@
+package cpu
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"sort"
+)
+
+// protoBuffer encodes the protocol buffer messages of the pprof format,
+// github.com/google/pprof/blob/main/proto/profile.proto.
+type protoBuffer []byte
+
+func (b *protoBuffer) varint(field int, v uint64) {
+	*b = binary.AppendUvarint(*b, uint64(field)<<3)
+	*b = binary.AppendUvarint(*b, v)
+}
+
+func (b *protoBuffer) bytes(field int, data []byte) {
+	*b = binary.AppendUvarint(*b, uint64(field)<<3|2)
+	*b = binary.AppendUvarint(*b, uint64(len(data)))
+	*b = append(*b, data...)
+}
+
+func (b *protoBuffer) packed(field int, values []uint64) {
+	var data protoBuffer
+
+	for _, v := range values {
+		data = binary.AppendUvarint(data, v)
+	}
+
+	b.bytes(field, data)
+}
+
+// WritePprof writes the profile in the gzipped protocol buffer format of
+// pprof, with one location per address and one function per subroutine:
+//
+//	go tool pprof -top out.prof
+func (p *Profile) WritePprof(w io.Writer) error {
+	strings := []string{""}
+	index := map[string]uint64{"": 0}
+
+	str := func(s string) uint64 {
+		if i, ok := index[s]; ok {
+			return i
+		}
+
+		index[s] = uint64(len(strings))
+		strings = append(strings, s)
+
+		return index[s]
+	}
+
+	type location struct{ pc, fn uint16 }
+
+	var out protoBuffer
+	var valueType protoBuffer
+	valueType.varint(1, str("instructions"))
+	valueType.varint(2, str("count"))
+	out.bytes(1, valueType)
+
+	locations := map[location]uint64{}
+	functions := map[uint16]uint64{}
+
+	keys := make([]profileStack, 0, len(p.samples))
+
+	for key := range p.samples {
+		keys = append(keys, key)
+	}
+
+	// Sorted for reproducible files
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+
+		if a.depth != b.depth {
+			return a.depth < b.depth
+		}
+
+		if a.pcs != b.pcs {
+			return lessStack(a.pcs[:], b.pcs[:])
+		}
+
+		return lessStack(a.funcs[:], b.funcs[:])
+	})
+
+	var locs protoBuffer
+	var funcs protoBuffer
+
+	for _, key := range keys {
+		ids := []uint64{}
+
+		for i := 0; i <= int(key.depth); i++ {
+			l := location{key.pcs[i], key.funcs[i]}
+			id, ok := locations[l]
+
+			if !ok {
+				fn, ok := functions[l.fn]
+
+				if !ok {
+					fn = uint64(len(functions) + 1)
+					functions[l.fn] = fn
+
+					var f protoBuffer
+					f.varint(1, fn)
+					f.varint(2, str(p.name(l.fn)))
+					f.varint(3, str(p.name(l.fn)))
+					funcs.bytes(5, f)
+				}
+
+				id = uint64(len(locations) + 1)
+				locations[l] = id
+
+				var line protoBuffer
+				line.varint(1, fn)
+
+				var loc protoBuffer
+				loc.varint(1, id)
+				loc.varint(3, uint64(l.pc))
+				loc.bytes(4, line)
+				locs.bytes(4, loc)
+			}
+
+			ids = append(ids, id)
+		}
+
+		var sample protoBuffer
+		sample.packed(1, ids)
+		sample.packed(2, []uint64{p.samples[key]})
+		out.bytes(2, sample)
+	}
+
+	out = append(out, locs...)
+	out = append(out, funcs...)
+
+	for _, s := range strings {
+		out.bytes(6, []byte(s))
+	}
+
+	out.bytes(11, valueType)
+	out.varint(12, 1)
+
+	z := gzip.NewWriter(w)
+
+	if _, err := z.Write(out); err != nil {
+		return err
+	}
+
+	return z.Close()
+}
+
+func lessStack(a []uint16, b []uint16) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return false
+}
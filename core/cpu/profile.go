@@ -0,0 +1,267 @@
+package cpu
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// PROFILE_TOP is how many addresses the text report lists.
+const PROFILE_TOP = 20
+
+// profileStack is the call stack of an instruction, the current address
+// and subroutine first and then every caller.
+type profileStack struct {
+	depth uint8
+	pcs   [17]uint16
+	funcs [17]uint16 // Entry address of the subroutine of every pc
+}
+
+// Profile counts the instructions run by a CPU, set it as CPU.Profile.
+// Cycles are attributed to the subroutines entered by 2NNN and left by
+// 00EE, the instructions outside of any subroutine to the start address.
+type Profile struct {
+	Mnemonic func(uint16) string      // Disassembly of the text report, optional
+	Name     func(addr uint16) string // Names subroutines, optional
+
+	total      uint64
+	executions [0x10000]uint64 // By address
+	opcodes    [0x10000]uint16 // Last instruction run at every address
+	counts     [0x10000]uint64 // By instruction
+	calls      map[uint16]uint64
+	callees    [17]uint16 // Subroutine entered at every depth of the stack
+	samples    map[profileStack]uint64
+}
+
+func NewProfile() *Profile {
+	return &Profile{
+		calls:   map[uint16]uint64{},
+		samples: map[profileStack]uint64{},
+	}
+}
+
+// add counts the instruction about to run.
+func (p *Profile) add(cpu *CPU, data uint16) {
+	if p.total == 0 {
+		p.callees[0] = cpu.Platform.StartAddress()
+	}
+
+	p.total++
+	p.executions[cpu.pc]++
+	p.opcodes[cpu.pc] = data
+	p.counts[data]++
+
+	stack := &cpu.mmu.Stack
+	depth := min(stack.Depth(), 16)
+	key := profileStack{depth: uint8(depth)}
+	key.pcs[0] = cpu.pc
+	key.funcs[0] = p.callees[depth]
+
+	for i, f := range stack.Frames()[:depth] {
+		key.pcs[i+1] = f.Caller()
+		key.funcs[i+1] = p.callees[f.Depth-1]
+	}
+
+	p.samples[key]++
+}
+
+// call records a 2NNN that left depth calls on the stack.
+func (p *Profile) call(addr uint16, depth int) {
+	p.calls[addr]++
+
+	if depth <= 16 {
+		p.callees[depth] = addr
+	}
+}
+
+// Total is the number of instructions counted.
+func (p *Profile) Total() uint64 {
+	return p.total
+}
+
+// ProfileCount is a line of the report.
+type ProfileCount struct {
+	Name  string
+	Addr  uint16
+	Count uint64
+}
+
+// HotSpots returns the n addresses run the most, the most first.
+func (p *Profile) HotSpots(n int) []ProfileCount {
+	hot := []ProfileCount{}
+
+	for addr, count := range &p.executions {
+		if count > 0 {
+			hot = append(hot, ProfileCount{Name: p.mnemonic(p.opcodes[addr]), Addr: uint16(addr), Count: count})
+		}
+	}
+
+	sortCounts(hot)
+
+	return hot[:min(n, len(hot))]
+}
+
+// Classes returns the instructions run by opcode class, such as 8XY4, the
+// most first.
+func (p *Profile) Classes() []ProfileCount {
+	byClass := map[string]uint64{}
+
+	for op, count := range &p.counts {
+		if count > 0 {
+			byClass[OpcodeClass(uint16(op))] += count
+		}
+	}
+
+	classes := []ProfileCount{}
+
+	for class, count := range byClass {
+		classes = append(classes, ProfileCount{Name: class, Count: count})
+	}
+
+	sortCounts(classes)
+
+	return classes
+}
+
+// Subroutine is the share of a subroutine in a profile.
+type Subroutine struct {
+	Name  string
+	Addr  uint16 // Entry address
+	Self  uint64 // Instructions run in the subroutine
+	Total uint64 // Including the subroutines it called
+	Calls uint64
+}
+
+// Subroutines returns every subroutine that ran, the most total first.
+func (p *Profile) Subroutines() []Subroutine {
+	byAddr := map[uint16]*Subroutine{}
+
+	get := func(addr uint16) *Subroutine {
+		if s, ok := byAddr[addr]; ok {
+			return s
+		}
+
+		s := &Subroutine{Name: p.name(addr), Addr: addr, Calls: p.calls[addr]}
+		byAddr[addr] = s
+
+		return s
+	}
+
+	for key, count := range p.samples {
+		get(key.funcs[0]).Self += count
+		seen := map[uint16]bool{}
+
+		for _, f := range key.funcs[:key.depth+1] {
+			if !seen[f] {
+				seen[f] = true
+				get(f).Total += count
+			}
+		}
+	}
+
+	subroutines := []Subroutine{}
+
+	for _, s := range byAddr {
+		subroutines = append(subroutines, *s)
+	}
+
+	sort.Slice(subroutines, func(i, j int) bool {
+		a, b := subroutines[i], subroutines[j]
+		return a.Total > b.Total || (a.Total == b.Total && a.Addr < b.Addr)
+	})
+
+	return subroutines
+}
+
+// WriteText writes the report as tables of hot spots, opcode classes and
+// subroutines.
+func (p *Profile) WriteText(w io.Writer) error {
+	t := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(t, "%d instructions\n\n", p.total)
+	fmt.Fprintln(t, "ADDR\tCOUNT\tSHARE\tINSTRUCTION")
+
+	for _, c := range p.HotSpots(PROFILE_TOP) {
+		instruction := strings.TrimSpace(fmt.Sprintf("%04X %s", p.opcodes[c.Addr], c.Name))
+		fmt.Fprintf(t, "%04X\t%d\t%s\t%s\n", c.Addr, c.Count, p.share(c.Count), instruction)
+	}
+
+	fmt.Fprintln(t, "\nCLASS\tCOUNT\tSHARE")
+
+	for _, c := range p.Classes() {
+		fmt.Fprintf(t, "%s\t%d\t%s\n", c.Name, c.Count, p.share(c.Count))
+	}
+
+	fmt.Fprintln(t, "\nSUBROUTINE\tSELF\tSHARE\tTOTAL\tSHARE\tCALLS")
+
+	for _, s := range p.Subroutines() {
+		fmt.Fprintf(t, "%s\t%d\t%s\t%d\t%s\t%d\n", s.Name, s.Self, p.share(s.Self), s.Total, p.share(s.Total), s.Calls)
+	}
+
+	return t.Flush()
+}
+
+func (p *Profile) share(count uint64) string {
+	return fmt.Sprintf("%.1f%%", 100*float64(count)/float64(max(p.total, 1)))
+}
+
+func (p *Profile) mnemonic(op uint16) string {
+	if p.Mnemonic == nil {
+		return ""
+	}
+
+	return p.Mnemonic(op)
+}
+
+// name names the subroutine at addr, by default sub_ADDR or main for the
+// start address.
+func (p *Profile) name(addr uint16) string {
+	if p.Name != nil {
+		if name := p.Name(addr); name != "" {
+			return name
+		}
+	}
+
+	if addr == p.callees[0] {
+		return "main"
+	}
+
+	return fmt.Sprintf("sub_%04X", addr)
+}
+
+func sortCounts(counts []ProfileCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		a, b := counts[i], counts[j]
+		return a.Count > b.Count || (a.Count == b.Count && a.Addr < b.Addr) || (a.Count == b.Count && a.Addr == b.Addr && a.Name < b.Name)
+	})
+}
+
+// OpcodeClass returns the pattern of an instruction with its operands as
+// letters, e.g. 8XY4 for 0x8124 or DXYN for 0xD125.
+func OpcodeClass(op uint16) string {
+	switch op >> 12 {
+	case 0x0:
+		switch {
+		case op&0xFFF0 == 0x00C0:
+			return "00CN"
+		case op&0xFFF0 == 0x00D0:
+			return "00DN"
+		case op&0xFF00 == 0x0000:
+			return fmt.Sprintf("%04X", op)
+		}
+
+		return "0NNN"
+	case 0x1, 0x2, 0xA, 0xB:
+		return fmt.Sprintf("%XNNN", op>>12)
+	case 0x5, 0x8, 0x9:
+		return fmt.Sprintf("%XXY%X", op>>12, op&0xF)
+	case 0xD:
+		return "DXYN"
+	case 0xE, 0xF:
+		return fmt.Sprintf("%XX%02X", op>>12, op&0xFF)
+	}
+
+	return fmt.Sprintf("%XXKK", op>>12)
+}
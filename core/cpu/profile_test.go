@@ -0,0 +1,111 @@
+package cpu
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+)
+
+// profileCPU runs loop: CALL draw; JP loop; draw: ADD V0, 1; CALL pixel;
+// RET; pixel: ADD V1, 1; RET for n instructions.
+func profileCPU(t *testing.T, n int) *Profile {
+	cpu := NewCpu()
+	cpu.LoadROM([]byte{0x22, 0x04, 0x12, 0x00, 0x70, 0x01, 0x22, 0x0C, 0x00, 0xEE, 0x00, 0x00, 0x71, 0x01, 0x00, 0xEE})
+	cpu.Profile = NewProfile()
+
+	for i := 0; i < n; i++ {
+		if err := cpu.Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return cpu.Profile
+}
+
+func TestProfile(t *testing.T) {
+	// 7 instructions per loop
+	p := profileCPU(t, 70)
+
+	if p.Total() != 70 {
+		t.Errorf("Total() = %d; expected 70", p.Total())
+	}
+
+	if hot := p.HotSpots(1); len(hot) != 1 || hot[0].Addr != 0x200 || hot[0].Count != 10 {
+		t.Errorf("HotSpots(1) = %v; expected 0x200 run 10 times", hot)
+	}
+
+	classes := map[string]uint64{}
+
+	for _, c := range p.Classes() {
+		classes[c.Name] = c.Count
+	}
+
+	if classes["2NNN"] != 20 || classes["00EE"] != 20 || classes["7XKK"] != 20 || classes["1NNN"] != 10 {
+		t.Errorf("Classes() = %v; expected 20 2NNN, 00EE and 7XKK and 10 1NNN", classes)
+	}
+
+	expected := []Subroutine{
+		{Name: "main", Addr: 0x200, Self: 20, Total: 70},
+		{Name: "sub_0204", Addr: 0x204, Self: 30, Total: 50, Calls: 10},
+		{Name: "sub_020C", Addr: 0x20C, Self: 20, Total: 20, Calls: 10},
+	}
+
+	subroutines := p.Subroutines()
+
+	if len(subroutines) != len(expected) {
+		t.Fatalf("Subroutines() = %v; expected %v", subroutines, expected)
+	}
+
+	for i := range expected {
+		if subroutines[i] != expected[i] {
+			t.Errorf("Subroutines()[%d] = %+v; expected %+v", i, subroutines[i], expected[i])
+		}
+	}
+}
+
+func TestProfileReports(t *testing.T) {
+	p := profileCPU(t, 70)
+	p.Name = func(addr uint16) string {
+		if addr == 0x204 {
+			return "draw"
+		}
+
+		return ""
+	}
+
+	var text bytes.Buffer
+
+	if err := p.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(text.String(), "draw") || !strings.Contains(text.String(), "2NNN") {
+		t.Errorf("WriteText() = %q; expected subroutines and classes", text.String())
+	}
+
+	var prof bytes.Buffer
+
+	if err := p.WritePprof(&prof); err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := gzip.NewReader(&prof)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(z)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"instructions", "main", "draw", "sub_020C"} {
+		if !bytes.Contains(data, []byte(s)) {
+			t.Errorf("pprof profile without %q", s)
+		}
+	}
+}
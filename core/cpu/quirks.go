@@ -0,0 +1,22 @@
+package cpu
+
+// Quirks toggles behaviors that differ between CHIP-8 implementations.
+type Quirks struct {
+	HalfScrollLores bool // Low resolution scrolls move half the high resolution distance
+	CollisionRows   bool // VF holds the number of colliding rows when drawing in high resolution
+	KeyPressOnly    bool // FX0A returns when a key is pressed instead of waiting for its release
+}
+
+// SCHIP 1.1 as it ran on the HP48 calculators.
+var LEGACY_QUIRKS = Quirks{
+	HalfScrollLores: true,
+	CollisionRows:   true,
+	KeyPressOnly:    false,
+}
+
+// Modern SUPER-CHIP and XO-CHIP as implemented by Octo.
+var MODERN_QUIRKS = Quirks{
+	HalfScrollLores: false,
+	CollisionRows:   false,
+	KeyPressOnly:    false,
+}
@@ -0,0 +1,18 @@
+package cpu
+
+// Seed restarts the random numbers of CXNN from seed, so that runs with the
+// same seed and input are identical. NewCpu seeds from the clock and Clone
+// copies the state, a restored save state draws the same numbers.
+func (cpu *CPU) Seed(seed uint64) {
+	cpu.random = seed
+}
+
+// randomByte steps the SplitMix64 generator.
+func (cpu *CPU) randomByte() byte {
+	cpu.random += 0x9E3779B97F4A7C15
+	z := cpu.random
+	z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+	z = (z ^ z>>27) * 0x94D049BB133111EB
+
+	return byte((z ^ z>>31) >> 56)
+}
@@ -0,0 +1,187 @@
+package cpu
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"testing"
+)
+
+func loadTestROM(t *testing.T, name string, quirks Quirks) CPU {
+	rom, err := os.ReadFile("../../cli/roms/test/" + name)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cpu := NewCpu()
+	cpu.Quirks = quirks
+	cpu.LoadROM(rom)
+
+	return cpu
+}
+
+// pressKeys runs the CPU for a while, then holds and releases each key as
+// the menus of the test ROMs expect.
+func pressKeys(cpu *CPU, keys ...uint8) {
+	run := func(n int) {
+		for i := 0; i < n; i++ {
+			cpu.Run()
+		}
+	}
+
+	run(5000)
+
+	for _, k := range keys {
+		cpu.Keys[k] = 0x01
+		run(300)
+		cpu.Keys[k] = 0x00
+		run(5000)
+	}
+}
+
+func displayHash(cpu *CPU) string {
+	h := sha1.New()
+
+	for y := 0; y < cpu.Graphics.Height; y++ {
+		for x := 0; x < cpu.Graphics.Width; x++ {
+			h.Write([]byte{cpu.Graphics.GetPixel(y, x)})
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func TestScrollingROM(t *testing.T) {
+	tests := []struct {
+		name     string
+		quirks   Quirks
+		keys     []uint8
+		expected string
+	}{
+		{"SCHIP legacy lores", LEGACY_QUIRKS, []uint8{0x1, 0x1, 0x2}, "261c3a20229efd40a459677bc0522159c1cfe823"},
+		{"SCHIP modern lores", MODERN_QUIRKS, []uint8{0x1, 0x1, 0x1}, "261c3a20229efd40a459677bc0522159c1cfe823"},
+		{"SCHIP hires", LEGACY_QUIRKS, []uint8{0x1, 0x2}, "002c924b782fc7d7e6361156829b63e540803d11"},
+		{"XO-CHIP lores", MODERN_QUIRKS, []uint8{0x2, 0x1}, "9fa593dd577acec77926178e799c57809940a4ef"},
+		{"XO-CHIP hires", MODERN_QUIRKS, []uint8{0x2, 0x2}, "acb9d691baf646f0576228fc5c43f22739806cbe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu := loadTestROM(t, "scrolling.ch8", tt.quirks)
+			pressKeys(&cpu, tt.keys...)
+
+			if h := displayHash(&cpu); h != tt.expected {
+				t.Errorf("display hash = %s; expected %s", h, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSCHIPTestROM(t *testing.T) {
+	cpu := loadTestROM(t, "schip-test.ch8", LEGACY_QUIRKS)
+	pressKeys(&cpu)
+
+	if cpu.pc != 0x238 && cpu.pc != 0x23A {
+		t.Errorf("cpu.pc = 0x%X; expected the final loop at 0x238", cpu.pc)
+	}
+
+	expected := "45c1612751d2d8f681b117a2f26838106bad9d90"
+
+	if h := displayHash(&cpu); h != expected {
+		t.Errorf("display hash = %s; expected %s", h, expected)
+	}
+}
+
+func TestSCHIPDRWCollisionRows(t *testing.T) {
+	cpu := NewCpu()
+	cpu.high()
+
+	cpu.i = 0x300
+
+	for i := uint16(0); i < 32; i++ {
+		cpu.mmu.Write(0x300+i, 0xFF)
+	}
+
+	cpu.mmu.Write(0x200, 0xD0)
+	cpu.mmu.Write(0x201, 0x10)
+	cpu.mmu.Write(0x202, 0xD0)
+	cpu.mmu.Write(0x203, 0x10)
+
+	cpu.clock()
+
+	if cpu.v[0xF] != 0x00 {
+		t.Errorf("cpu.v[0xF] = 0x%X; expected 0x00", cpu.v[0xF])
+	}
+
+	cpu.v[0x1] = 0x08
+	cpu.clock()
+
+	if cpu.v[0xF] != 0x08 {
+		t.Errorf("cpu.v[0xF] = 0x%X; expected 0x08 colliding rows", cpu.v[0xF])
+	}
+}
+
+func TestSCHIPDRWClippedRows(t *testing.T) {
+	cpu := NewCpu()
+	cpu.high()
+
+	cpu.i = 0x300
+	cpu.v[0x1] = 0x3C
+
+	cpu.mmu.Write(0x200, 0xD0)
+	cpu.mmu.Write(0x201, 0x10)
+
+	cpu.clock()
+
+	if cpu.v[0xF] != 0x0C {
+		t.Errorf("cpu.v[0xF] = 0x%X; expected 0x0C clipped rows", cpu.v[0xF])
+	}
+
+	cpu = NewCpu()
+	cpu.high()
+	cpu.Quirks = MODERN_QUIRKS
+
+	cpu.i = 0x300
+	cpu.v[0x1] = 0x3C
+
+	cpu.mmu.Write(0x200, 0xD0)
+	cpu.mmu.Write(0x201, 0x10)
+
+	cpu.clock()
+
+	if cpu.v[0xF] != 0x00 {
+		t.Errorf("cpu.v[0xF] = 0x%X; expected 0x00", cpu.v[0xF])
+	}
+}
+
+func TestScrollLoresHalfPixels(t *testing.T) {
+	cpu := NewCpu()
+
+	cpu.Graphics.SetPixel(0, 0, 0x1)
+
+	cpu.mmu.Write(0x200, 0x00)
+	cpu.mmu.Write(0x201, 0xFB)
+	cpu.mmu.Write(0x202, 0x00)
+	cpu.mmu.Write(0x203, 0xC4)
+	cpu.mmu.Write(0x204, 0x00)
+	cpu.mmu.Write(0x205, 0xD2)
+
+	cpu.clock()
+
+	if cpu.Graphics.GetPixel(0, 2) != 0x1 {
+		t.Error("pixel expected at (0, 2) after scrolling right in lores")
+	}
+
+	cpu.clock()
+
+	if cpu.Graphics.GetPixel(2, 2) != 0x1 {
+		t.Error("pixel expected at (2, 2) after scrolling down 4 in lores")
+	}
+
+	cpu.clock()
+
+	if cpu.Graphics.GetPixel(1, 2) != 0x1 {
+		t.Error("pixel expected at (1, 2) after scrolling up 2 in lores")
+	}
+}
@@ -0,0 +1,336 @@
+package cpu
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const (
+	TRACE_TEXT   = "text"
+	TRACE_BINARY = "binary"
+)
+
+// TRACE_MAGIC starts binary traces, text traces have no header.
+const TRACE_MAGIC = "C8TR"
+
+// TraceEntry is the state of the machine before an instruction runs.
+type TraceEntry struct {
+	Cycle      uint64 // Instructions run before this one
+	PC         uint16
+	Opcode     uint16
+	I          uint32
+	V          [16]uint8
+	DelayTimer uint8
+	SoundTimer uint8
+	SP         uint8
+}
+
+// TraceRange is an inclusive range of instruction addresses.
+type TraceRange struct {
+	Start uint16
+	End   uint16
+}
+
+// Trace writes one entry per instruction run by a CPU, set it as CPU.Trace.
+type Trace struct {
+	Format   string
+	Ranges   []TraceRange        // Addresses traced, all of them when empty
+	Mnemonic func(uint16) string // Disassembly of text entries, optional
+
+	w      *bufio.Writer
+	closer io.Closer
+	header bool
+}
+
+// NewTrace writes a trace in format to w, which is closed by Close when it
+// is an io.Closer.
+func NewTrace(w io.Writer, format string) (*Trace, error) {
+	if format != TRACE_TEXT && format != TRACE_BINARY {
+		return nil, fmt.Errorf("unknown trace format %q", format)
+	}
+
+	t := &Trace{
+		Format: format,
+		w:      bufio.NewWriter(w),
+	}
+
+	if c, ok := w.(io.Closer); ok {
+		t.closer = c
+	}
+
+	return t, nil
+}
+
+// Traces reports whether the instruction at pc is traced.
+func (t *Trace) Traces(pc uint16) bool {
+	if len(t.Ranges) == 0 {
+		return true
+	}
+
+	for _, r := range t.Ranges {
+		if pc >= r.Start && pc <= r.End {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *Trace) Write(e TraceEntry) error {
+	if t.Format == TRACE_TEXT {
+		_, err := t.w.WriteString(e.Format(t.Mnemonic) + "\n")
+		return err
+	}
+
+	if !t.header {
+		t.header = true
+
+		if _, err := t.w.WriteString(TRACE_MAGIC); err != nil {
+			return err
+		}
+	}
+
+	return binary.Write(t.w, binary.BigEndian, e)
+}
+
+// Flush writes the buffered entries, nil traces are ignored so callers do
+// not have to check whether tracing is on.
+func (t *Trace) Flush() error {
+	if t == nil {
+		return nil
+	}
+
+	return t.w.Flush()
+}
+
+func (t *Trace) Close() error {
+	if t == nil {
+		return nil
+	}
+
+	err := t.w.Flush()
+
+	if t.closer != nil {
+		err = errors.Join(err, t.closer.Close())
+	}
+
+	return err
+}
+
+// Format returns the text form of the entry, followed by the disassembly
+// of the opcode when mnemonic is not nil.
+func (e TraceEntry) Format(mnemonic func(uint16) string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d pc=%04X op=%04X i=%04X v=", e.Cycle, e.PC, e.Opcode, e.I)
+
+	for x, v := range e.V {
+		if x > 0 {
+			b.WriteByte(',')
+		}
+
+		fmt.Fprintf(&b, "%02X", v)
+	}
+
+	fmt.Fprintf(&b, " dt=%02X st=%02X sp=%X", e.DelayTimer, e.SoundTimer, e.SP)
+
+	if mnemonic != nil {
+		if m := mnemonic(e.Opcode); m != "" {
+			b.WriteString(" ; " + m)
+		}
+	}
+
+	return b.String()
+}
+
+// TraceReader reads the entries of a text or binary trace.
+type TraceReader struct {
+	r      *bufio.Reader
+	binary bool
+	line   int
+}
+
+func NewTraceReader(r io.Reader) (*TraceReader, error) {
+	t := &TraceReader{r: bufio.NewReader(r)}
+
+	magic, err := t.r.Peek(len(TRACE_MAGIC))
+
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if bytes.Equal(magic, []byte(TRACE_MAGIC)) {
+		t.binary = true
+		t.r.Discard(len(TRACE_MAGIC))
+	}
+
+	return t, nil
+}
+
+// Next returns the next entry, or io.EOF at the end of the trace.
+func (t *TraceReader) Next() (TraceEntry, error) {
+	var e TraceEntry
+
+	if t.binary {
+		err := binary.Read(t.r, binary.BigEndian, &e)
+
+		if err == io.ErrUnexpectedEOF {
+			err = errors.New("truncated binary trace")
+		}
+
+		return e, err
+	}
+
+	for {
+		line, err := t.r.ReadString('\n')
+
+		if err != nil && (err != io.EOF || line == "") {
+			return e, err
+		}
+
+		t.line++
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		e, err := ParseTraceEntry(line)
+
+		if err != nil {
+			return e, fmt.Errorf("line %d: %w", t.line, err)
+		}
+
+		return e, nil
+	}
+}
+
+// ParseTraceEntry parses the text form of an entry, the disassembly after
+// ';' is ignored.
+func ParseTraceEntry(line string) (TraceEntry, error) {
+	var e TraceEntry
+
+	line, _, _ = strings.Cut(line, ";")
+	fields := strings.Fields(line)
+
+	if len(fields) == 0 {
+		return e, errors.New("empty trace entry")
+	}
+
+	cycle, err := strconv.ParseUint(fields[0], 10, 64)
+
+	if err != nil {
+		return e, fmt.Errorf("invalid cycle %q", fields[0])
+	}
+
+	e.Cycle = cycle
+
+	for _, f := range fields[1:] {
+		key, value, _ := strings.Cut(f, "=")
+
+		switch key {
+		case "pc":
+			err = parseHex(value, 16, &e.PC)
+		case "op":
+			err = parseHex(value, 16, &e.Opcode)
+		case "i":
+			err = parseHex(value, 32, &e.I)
+		case "dt":
+			err = parseHex(value, 8, &e.DelayTimer)
+		case "st":
+			err = parseHex(value, 8, &e.SoundTimer)
+		case "sp":
+			err = parseHex(value, 8, &e.SP)
+		case "v":
+			registers := strings.Split(value, ",")
+
+			if len(registers) != len(e.V) {
+				return e, fmt.Errorf("expected %d registers, got %d", len(e.V), len(registers))
+			}
+
+			for x, r := range registers {
+				if err = parseHex(r, 8, &e.V[x]); err != nil {
+					break
+				}
+			}
+		default:
+			return e, fmt.Errorf("unknown field %q", f)
+		}
+
+		if err != nil {
+			return e, fmt.Errorf("invalid field %q", f)
+		}
+	}
+
+	return e, nil
+}
+
+func parseHex[T uint8 | uint16 | uint32](s string, bits int, v *T) error {
+	n, err := strconv.ParseUint(s, 16, bits)
+	*v = T(n)
+
+	return err
+}
+
+// ParseTraceRanges parses comma separated address ranges such as
+// "200-2FF,0x400-0x4FF", a single address is a range of its own.
+func ParseTraceRanges(s string) ([]TraceRange, error) {
+	var ranges []TraceRange
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+
+		if part == "" {
+			continue
+		}
+
+		start, end, found := strings.Cut(part, "-")
+
+		if !found {
+			end = start
+		}
+
+		var r TraceRange
+
+		if err := parseHex(strings.TrimPrefix(strings.TrimSpace(start), "0x"), 16, &r.Start); err != nil {
+			return nil, fmt.Errorf("invalid trace range %q", part)
+		}
+
+		if err := parseHex(strings.TrimPrefix(strings.TrimSpace(end), "0x"), 16, &r.End); err != nil || r.End < r.Start {
+			return nil, fmt.Errorf("invalid trace range %q", part)
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges, nil
+}
+
+// trace writes the entry of the instruction about to run.
+func (cpu *CPU) trace(data uint16) {
+	if !cpu.Trace.Traces(cpu.pc) {
+		return
+	}
+
+	e := TraceEntry{
+		Cycle:      cpu.cycle,
+		PC:         cpu.pc,
+		Opcode:     data,
+		I:          cpu.addr(),
+		V:          cpu.v,
+		DelayTimer: cpu.delayTimer,
+		SoundTimer: cpu.SoundTimer,
+		SP:         uint8(cpu.mmu.Stack.SP),
+	}
+
+	if err := cpu.Trace.Write(e); err != nil {
+		log.Printf("trace: %v", err)
+		cpu.Trace = nil
+	}
+}
@@ -0,0 +1,128 @@
+package cpu
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func traceROM(t *testing.T, format string, ranges []TraceRange) []TraceEntry {
+	var b bytes.Buffer
+
+	trace, err := NewTrace(&b, format)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trace.Ranges = ranges
+
+	cpu := NewCpu()
+	cpu.Trace = trace
+
+	// LD V0, 0x12; LD I, 0x300; ADD V0, 1; JP 0x204
+	cpu.LoadROM([]byte{0x60, 0x12, 0xA3, 0x00, 0x70, 0x01, 0x12, 0x04})
+
+	for i := 0; i < 6; i++ {
+		cpu.Run()
+	}
+
+	trace.Close()
+
+	r, err := NewTraceReader(&b)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := []TraceEntry{}
+
+	for {
+		e, err := r.Next()
+
+		if err == io.EOF {
+			return entries
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entries = append(entries, e)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	for _, format := range []string{TRACE_TEXT, TRACE_BINARY} {
+		entries := traceROM(t, format, nil)
+
+		if len(entries) != 6 {
+			t.Fatalf("%s: len(entries) = %d; expected 6", format, len(entries))
+		}
+
+		e := entries[4]
+
+		if e.Cycle != 4 || e.PC != 0x204 || e.Opcode != 0x7001 {
+			t.Errorf("%s: entry = %d %04X %04X; expected 4 0204 7001", format, e.Cycle, e.PC, e.Opcode)
+		}
+
+		if e.I != 0x300 {
+			t.Errorf("%s: e.I = 0x%X; expected 0x300", format, e.I)
+		}
+
+		if e.V[0] != 0x13 {
+			t.Errorf("%s: e.V[0] = 0x%X; expected 0x13", format, e.V[0])
+		}
+	}
+}
+
+func TestTraceRanges(t *testing.T) {
+	entries := traceROM(t, TRACE_TEXT, []TraceRange{{0x204, 0x205}})
+
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d; expected 2", len(entries))
+	}
+
+	for _, e := range entries {
+		if e.PC != 0x204 {
+			t.Errorf("e.PC = 0x%X; expected 0x204", e.PC)
+		}
+	}
+}
+
+func TestParseTraceEntry(t *testing.T) {
+	e := TraceEntry{Cycle: 7, PC: 0x2A0, Opcode: 0xD015, I: 0x12345, DelayTimer: 0x3C, SP: 2}
+	e.V[0xF] = 0x01
+
+	parsed, err := ParseTraceEntry(e.Format(func(uint16) string { return "DRW V0, V1, 5" }))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed != e {
+		t.Errorf("parsed = %+v; expected %+v", parsed, e)
+	}
+
+	if _, err := ParseTraceEntry("1 pc=ZZZZ"); err == nil {
+		t.Errorf("expected an error for an invalid pc")
+	}
+}
+
+func TestParseTraceRanges(t *testing.T) {
+	ranges, err := ParseTraceRanges("200-2FF, 0x400")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []TraceRange{{0x200, 0x2FF}, {0x400, 0x400}}
+
+	if len(ranges) != len(expected) || ranges[0] != expected[0] || ranges[1] != expected[1] {
+		t.Errorf("ranges = %v; expected %v", ranges, expected)
+	}
+
+	if _, err := ParseTraceRanges("300-200"); err == nil {
+		t.Errorf("expected an error for a reversed range")
+	}
+}
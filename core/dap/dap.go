@@ -0,0 +1,713 @@
+// Package dap is a Debug Adapter Protocol server, editors attach to the
+// machine over TCP to launch a ROM and debug it.
+package dap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/debugserver"
+	"github.com/gaoliveira21/chip8/core/disasm"
+)
+
+const (
+	THREAD_ID = 1
+
+	// Source reference of the disassembly listing of the ROM, shown when
+	// there is no source map.
+	DISASSEMBLY_REFERENCE = 1
+
+	REGISTERS_REFERENCE = 1
+	TIMERS_REFERENCE    = 2
+)
+
+// Server serves one editor at a time. Like the GDB server, the machine is
+// only touched by the goroutine calling Poll and Stopped.
+type Server struct {
+	CPU *cpu.CPU
+
+	// Load starts a ROM on a new machine, which the frontend then sets as
+	// CPU. Required by the launch request.
+	Load func(rom []byte, title string) error
+
+	*debugserver.Server[*conn, *request]
+	rom         []byte
+	title       string
+	symbols     *disasm.Symbols
+	stopOnEntry bool
+	breakpoints map[string][]int // Breakpoint IDs set by the editor, by source
+	steps       []int            // Breakpoint IDs of the step in progress
+	stepped     bool             // A single step completed, stepErr is its result
+	stepErr     error
+}
+
+// Listen starts a server on addr, e.g. "localhost:4711" or ":4711", which
+// must be a loopback address: launch reads any file the emulator can.
+func Listen(addr string) (*Server, error) {
+	addr, err := debugserver.Loopback(addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{breakpoints: map[string][]int{}}
+
+	s.Server, err = debugserver.Listen(addr, newConn, debugserver.Handlers[*conn, *request]{
+		Handle: s.dispatch,
+		Detach: s.detach,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// SetCPU serves c, the frontend calls it when the machine is replaced.
+func (s *Server) SetCPU(c *cpu.CPU) {
+	s.CPU = c
+}
+
+// SetLoad sets Load, for frontends that only see a core.Launcher.
+func (s *Server) SetLoad(load func(rom []byte, title string) error) {
+	s.Load = load
+}
+
+// Poll handles the pending requests of the editor and reports whether the
+// machine can run. A nil server always lets it run.
+func (s *Server) Poll() bool {
+	return s == nil || s.Server.Poll()
+}
+
+// Stopped reports an error returned by CPU.Run to the editor and halts the
+// machine, it returns false for nil errors and when no editor is attached.
+func (s *Server) Stopped(err error) bool {
+	if err == nil || s == nil || !s.Attached() {
+		return false
+	}
+
+	s.stop(err)
+
+	return true
+}
+
+// stop halts the machine and sends the stopped event for the result of
+// CPU.Run, nil for a completed step.
+func (s *Server) stop(err error) {
+	body := map[string]any{"threadId": THREAD_ID, "allThreadsStopped": true}
+	var b *cpu.BreakError
+
+	switch {
+	case errors.As(err, &b) && s.isStep(b.Breakpoint.ID):
+		body["reason"] = "step"
+	case errors.As(err, &b) && b.Breakpoint.Kind == cpu.BREAK_WRITE:
+		body["reason"] = "data breakpoint"
+		body["description"] = b.Error()
+	case errors.As(err, &b):
+		body["reason"] = "breakpoint"
+		body["hitBreakpointIds"] = []int{b.Breakpoint.ID}
+	case err != nil:
+		body["reason"] = "exception"
+		body["text"] = err.Error()
+	default:
+		body["reason"] = "step"
+	}
+
+	s.endStep()
+	s.Halted = true
+	s.Client.event("stopped", body)
+}
+
+func (s *Server) isStep(id int) bool {
+	for _, step := range s.steps {
+		if step == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// endStep removes the breakpoints of the step in progress.
+func (s *Server) endStep() {
+	for _, id := range s.steps {
+		s.CPU.Breakpoints.Remove(id)
+	}
+
+	s.steps = nil
+}
+
+// detach removes the breakpoints of the editor.
+func (s *Server) detach() {
+	if s.CPU != nil {
+		s.endStep()
+
+		for _, ids := range s.breakpoints {
+			for _, id := range ids {
+				s.CPU.Breakpoints.Remove(id)
+			}
+		}
+	}
+
+	s.breakpoints = map[string][]int{}
+}
+
+func (s *Server) dispatch(req *request) {
+	handlers := map[string]func(args json.RawMessage) (any, error){
+		"initialize":        s.initialize,
+		"launch":            s.launch,
+		"configurationDone": s.configurationDone,
+		"setBreakpoints":    s.setBreakpoints,
+		"threads":           s.threads,
+		"stackTrace":        s.stackTrace,
+		"scopes":            s.scopes,
+		"variables":         s.variables,
+		"setVariable":       s.setVariable,
+		"evaluate":          s.evaluate,
+		"source":            s.source,
+		"continue":          s.resume,
+		"next":              s.next,
+		"stepIn":            s.stepIn,
+		"stepOut":           s.stepOut,
+		"pause":             s.pause,
+		"disconnect":        s.disconnect,
+		"terminate":         s.disconnect,
+	}
+
+	res := &response{RequestSeq: req.Seq, Command: req.Command, Success: true}
+	handler, ok := handlers[req.Command]
+
+	if !ok {
+		res.Success = false
+		res.Message = fmt.Sprintf("unsupported request %q", req.Command)
+		s.Client.write(res)
+		return
+	}
+
+	if s.CPU == nil && req.Command != "initialize" && req.Command != "launch" && req.Command != "disconnect" {
+		res.Success = false
+		res.Message = "no machine running"
+		s.Client.write(res)
+		return
+	}
+
+	conn := s.Client
+	body, err := handler(req.Arguments)
+
+	if err != nil {
+		res.Success = false
+		res.Message = err.Error()
+	}
+
+	res.Body = body
+	conn.write(res)
+
+	switch {
+	case err != nil:
+	case req.Command == "launch":
+		conn.event("initialized", nil)
+	case req.Command == "disconnect" || req.Command == "terminate":
+		conn.Close()
+	case s.stepped:
+		s.stepped = false
+		s.stop(s.stepErr)
+	}
+}
+
+func (s *Server) initialize(json.RawMessage) (any, error) {
+	return map[string]any{
+		"supportsConfigurationDoneRequest":  true,
+		"supportsConditionalBreakpoints":    true,
+		"supportsHitConditionalBreakpoints": true,
+		"supportsSetVariable":               true,
+		"supportsEvaluateForHovers":         true,
+		"supportsTerminateRequest":          true,
+	}, nil
+}
+
+func (s *Server) launch(args json.RawMessage) (any, error) {
+	var a struct {
+		Rom         string   `json:"rom"`
+		SourceMap   string   `json:"sourceMap"`
+		Symbols     []string `json:"symbols"`
+		StopOnEntry bool     `json:"stopOnEntry"`
+	}
+
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, err
+	}
+
+	if s.Load == nil {
+		return nil, errors.New("this frontend cannot load ROMs")
+	}
+
+	rom, err := os.ReadFile(a.Rom)
+
+	if err != nil {
+		return nil, err
+	}
+
+	paths := a.Symbols
+
+	if a.SourceMap != "" {
+		paths = append(paths, a.SourceMap)
+	}
+
+	s.symbols = nil
+
+	if len(paths) > 0 {
+		if s.symbols, err = disasm.LoadSymbols(paths...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Load(rom, filepath.Base(a.Rom)); err != nil {
+		return nil, err
+	}
+
+	s.cpuBreakpoints()
+	s.rom = rom
+	s.title = filepath.Base(a.Rom)
+	s.stopOnEntry = a.StopOnEntry
+	s.Halted = true
+
+	return nil, nil
+}
+
+func (s *Server) configurationDone(json.RawMessage) (any, error) {
+	if s.stopOnEntry {
+		s.Client.event("stopped", map[string]any{"reason": "entry", "threadId": THREAD_ID, "allThreadsStopped": true})
+		return nil, nil
+	}
+
+	s.Halted = false
+
+	return nil, nil
+}
+
+// start is the address of the first instruction of the listing.
+func (s *Server) start() uint16 {
+	return s.CPU.Platform.StartAddress()
+}
+
+// location returns the source and line of addr, in the symbols or else in
+// the disassembly listing.
+func (s *Server) location(addr uint16) (*source, int) {
+	if s.symbols != nil {
+		if l, ok := s.symbols.Line(addr); ok {
+			return &source{Name: filepath.Base(l.File), Path: l.File}, l.Line
+		}
+	}
+
+	if s.rom == nil || addr < s.start() || int(addr-s.start()) >= len(s.rom) {
+		return nil, 0
+	}
+
+	lines, addrs := s.listing()
+	line := 0
+
+	for i := 0; i < len(lines) && addrs[i] <= addr; i++ {
+		if !isLabel(lines[i]) {
+			line = i + 1
+		}
+	}
+
+	return s.listingSource(), line
+}
+
+// listing returns the disassembly listing of the ROM and the address of
+// every line, labels have the address of the instruction after them.
+func (s *Server) listing() ([]string, []uint16) {
+	lines := disasm.Listing(s.rom, s.start(), s.symbols)
+	addrs := make([]uint16, len(lines))
+	addr := s.start()
+
+	for i, l := range lines {
+		addrs[i] = addr
+
+		if !isLabel(l) {
+			addr += 2
+		}
+	}
+
+	return lines, addrs
+}
+
+func isLabel(line string) bool {
+	return strings.HasSuffix(line, ":\n")
+}
+
+func (s *Server) listingSource() *source {
+	return &source{Name: s.title + ".dis", SourceReference: DISASSEMBLY_REFERENCE}
+}
+
+// address returns the address of a source line.
+func (s *Server) address(src source, line int) (uint16, error) {
+	if src.SourceReference == DISASSEMBLY_REFERENCE {
+		lines, addrs := s.listing()
+
+		if line < 1 || line > len(lines) {
+			return 0, fmt.Errorf("line %d is not in the ROM", line)
+		}
+
+		return addrs[line-1], nil
+	}
+
+	if s.symbols == nil {
+		return 0, errors.New("no source map")
+	}
+
+	if addr, ok := s.symbols.Addr(src.Path, line); ok {
+		return addr, nil
+	}
+
+	return 0, fmt.Errorf("no instruction at line %d", line)
+}
+
+func (s *Server) setBreakpoints(args json.RawMessage) (any, error) {
+	var a struct {
+		Source      source             `json:"source"`
+		Breakpoints []sourceBreakpoint `json:"breakpoints"`
+	}
+
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, err
+	}
+
+	breakpoints := s.cpuBreakpoints()
+	key := a.Source.Path
+
+	if a.Source.SourceReference != 0 {
+		key = strconv.Itoa(a.Source.SourceReference)
+	}
+
+	for _, id := range s.breakpoints[key] {
+		breakpoints.Remove(id)
+	}
+
+	s.breakpoints[key] = nil
+	result := []breakpoint{}
+
+	for _, sb := range a.Breakpoints {
+		b, err := s.newBreakpoint(a.Source, sb)
+
+		if err != nil {
+			result = append(result, breakpoint{Verified: false, Message: err.Error(), Line: sb.Line})
+			continue
+		}
+
+		breakpoints.Add(b)
+		s.breakpoints[key] = append(s.breakpoints[key], b.ID)
+		result = append(result, breakpoint{ID: b.ID, Verified: true, Source: &a.Source, Line: sb.Line})
+	}
+
+	return map[string]any{"breakpoints": result}, nil
+}
+
+func (s *Server) newBreakpoint(src source, sb sourceBreakpoint) (*cpu.Breakpoint, error) {
+	addr, err := s.address(src, sb.Line)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b := &cpu.Breakpoint{Kind: cpu.BREAK_PC, Start: uint32(addr), End: uint32(addr)}
+
+	if sb.Condition != "" {
+		if b.Condition, err = cpu.ParseCondition(sb.Condition); err != nil {
+			return nil, err
+		}
+	}
+
+	if sb.HitCondition != "" {
+		n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(sb.HitCondition), ">=")))
+
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid hit count %q", sb.HitCondition)
+		}
+
+		b.HitCount = n
+	}
+
+	return b, nil
+}
+
+// cpuBreakpoints returns the breakpoints of the machine, adding them when
+// it has none.
+func (s *Server) cpuBreakpoints() *cpu.Breakpoints {
+	if s.CPU.Breakpoints == nil {
+		s.CPU.Breakpoints = &cpu.Breakpoints{}
+	}
+
+	return s.CPU.Breakpoints
+}
+
+func (s *Server) threads(json.RawMessage) (any, error) {
+	return map[string]any{
+		"threads": []map[string]any{{"id": THREAD_ID, "name": "CHIP-8"}},
+	}, nil
+}
+
+// frames returns the addresses of the stack frames, the current
+// instruction first and then the calls, most recent first.
+func (s *Server) frames() []uint16 {
+	frames := []uint16{s.CPU.PC()}
+	stack := s.CPU.Stack()
+
+	for _, f := range stack.Frames() {
+		frames = append(frames, f.Caller())
+	}
+
+	return frames
+}
+
+// frameName names a stack frame after the label of addr, e.g. "draw+6",
+// or its address.
+func (s *Server) frameName(addr uint16) string {
+	if name := s.symbols.Name(addr); name != "" {
+		return fmt.Sprintf("%s (%04X)", name, addr)
+	}
+
+	return fmt.Sprintf("%04X", addr)
+}
+
+func (s *Server) stackTrace(json.RawMessage) (any, error) {
+	frames := []stackFrame{}
+
+	for id, addr := range s.frames() {
+		f := stackFrame{
+			ID:                    id,
+			Name:                  s.frameName(addr),
+			InstructionPointerRef: fmt.Sprintf("0x%04X", addr),
+			Column:                1,
+		}
+
+		f.Source, f.Line = s.location(addr)
+
+		if f.Source == nil {
+			f.PresentationHint = "subtle"
+		}
+
+		frames = append(frames, f)
+	}
+
+	return map[string]any{"stackFrames": frames, "totalFrames": len(frames)}, nil
+}
+
+func (s *Server) scopes(json.RawMessage) (any, error) {
+	return map[string]any{
+		"scopes": []scope{
+			{Name: "Registers", VariablesReference: REGISTERS_REFERENCE},
+			{Name: "Timers", VariablesReference: TIMERS_REFERENCE},
+		},
+	}, nil
+}
+
+// registers returns the names of the variables of a scope.
+func registers(reference int) []string {
+	if reference == TIMERS_REFERENCE {
+		return []string{"DT", "ST"}
+	}
+
+	names := []string{}
+
+	for x := 0; x < 16; x++ {
+		names = append(names, fmt.Sprintf("V%X", x))
+	}
+
+	return append(names, "I", "PC", "SP")
+}
+
+func (s *Server) variables(args json.RawMessage) (any, error) {
+	var a struct {
+		VariablesReference int `json:"variablesReference"`
+	}
+
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, err
+	}
+
+	variables := []variable{}
+
+	for _, name := range registers(a.VariablesReference) {
+		variables = append(variables, variable{Name: name, Value: s.value(name)})
+	}
+
+	return map[string]any{"variables": variables}, nil
+}
+
+// value formats a register as hexadecimal, 2 digits for bytes.
+func (s *Server) value(name string) string {
+	c, _ := cpu.ParseCondition(name)
+	v := c.Value(s.CPU)
+
+	if name[0] == 'V' || name == "SP" || name == "DT" || name == "ST" {
+		return fmt.Sprintf("0x%02X", v)
+	}
+
+	return fmt.Sprintf("0x%04X", v)
+}
+
+func (s *Server) setVariable(args json.RawMessage) (any, error) {
+	var a struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.ParseUint(strings.TrimSpace(a.Value), 0, 32)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q", a.Value)
+	}
+
+	switch name := strings.ToUpper(a.Name); {
+	case len(name) == 2 && name[0] == 'V':
+		x, err := strconv.ParseUint(name[1:], 16, 4)
+
+		if err != nil {
+			return nil, fmt.Errorf("unknown register %q", a.Name)
+		}
+
+		s.CPU.SetV(uint8(x), uint8(n))
+	case name == "I":
+		s.CPU.SetAddr(uint32(n) & 0xFFFFFF)
+	case name == "PC":
+		s.CPU.SetPC(uint16(n))
+	case name == "SP":
+		s.CPU.Memory().Stack.SP = uint16(min(n, 16))
+	case name == "DT":
+		s.CPU.SetDelayTimer(uint8(n))
+	case name == "ST":
+		s.CPU.SoundTimer = uint8(n)
+	default:
+		return nil, fmt.Errorf("unknown register %q", a.Name)
+	}
+
+	return map[string]any{"value": s.value(strings.ToUpper(a.Name))}, nil
+}
+
+// evaluate computes expressions in the syntax of breakpoint conditions.
+func (s *Server) evaluate(args json.RawMessage) (any, error) {
+	var a struct {
+		Expression string `json:"expression"`
+	}
+
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, err
+	}
+
+	c, err := cpu.ParseCondition(a.Expression)
+
+	if err != nil {
+		if addr, rerr := s.symbols.Resolve(strings.TrimSpace(a.Expression)); rerr == nil {
+			return map[string]any{"result": fmt.Sprintf("0x%04X", addr), "variablesReference": 0}, nil
+		}
+
+		return nil, err
+	}
+
+	v := c.Value(s.CPU)
+
+	return map[string]any{"result": fmt.Sprintf("0x%X (%d)", v, v), "variablesReference": 0}, nil
+}
+
+// source returns the disassembly listing, one instruction per line with
+// its label.
+func (s *Server) source(args json.RawMessage) (any, error) {
+	var a struct {
+		SourceReference int `json:"sourceReference"`
+	}
+
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, err
+	}
+
+	if a.SourceReference != DISASSEMBLY_REFERENCE || s.rom == nil {
+		return nil, fmt.Errorf("unknown source %d", a.SourceReference)
+	}
+
+	lines, _ := s.listing()
+
+	return map[string]any{"content": strings.Join(lines, ""), "mimeType": "text/plain"}, nil
+}
+
+func (s *Server) resume(json.RawMessage) (any, error) {
+	s.Halted = false
+	return map[string]any{"allThreadsContinued": true}, nil
+}
+
+func (s *Server) pause(json.RawMessage) (any, error) {
+	if !s.Halted {
+		s.endStep()
+		s.Halted = true
+		s.Client.event("stopped", map[string]any{"reason": "pause", "threadId": THREAD_ID, "allThreadsStopped": true})
+	}
+
+	return nil, nil
+}
+
+// stepIn runs a single instruction, the stopped event is sent after the
+// response.
+func (s *Server) stepIn(json.RawMessage) (any, error) {
+	err := s.CPU.Run()
+	var b *cpu.BreakError
+
+	// A breakpoint of the current instruction stops before it runs
+	if errors.As(err, &b) && b.Breakpoint.Kind != cpu.BREAK_WRITE {
+		err = s.CPU.Run()
+	}
+
+	s.stepped = true
+	s.stepErr = err
+
+	return nil, nil
+}
+
+// next steps over subroutine calls: after a 2NNN the machine runs until the
+// call returns.
+func (s *Server) next(args json.RawMessage) (any, error) {
+	if s.CPU.Opcode()&0xF000 != 0x2000 {
+		return s.stepIn(args)
+	}
+
+	sp := s.CPU.Stack().SP
+	c, _ := cpu.ParseCondition(fmt.Sprintf("SP == %d", sp))
+
+	return s.runUntil(&cpu.Breakpoint{Kind: cpu.BREAK_PC, Start: uint32(s.CPU.PC() + 2), End: uint32(s.CPU.PC() + 2), Condition: c})
+}
+
+// stepOut runs until the current subroutine returns.
+func (s *Server) stepOut(args json.RawMessage) (any, error) {
+	sp := s.CPU.Stack().SP
+
+	if sp == 0 {
+		return s.stepIn(args)
+	}
+
+	c, _ := cpu.ParseCondition(fmt.Sprintf("SP < %d", sp))
+
+	return s.runUntil(&cpu.Breakpoint{Kind: cpu.BREAK_CONDITION, Condition: c})
+}
+
+// runUntil resumes the machine until the step breakpoint b is hit.
+func (s *Server) runUntil(b *cpu.Breakpoint) (any, error) {
+	s.steps = append(s.steps, s.cpuBreakpoints().Add(b).ID)
+	s.Halted = false
+
+	return nil, nil
+}
+
+func (s *Server) disconnect(json.RawMessage) (any, error) {
+	return nil, nil
+}
@@ -0,0 +1,393 @@
+package dap_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/dap"
+)
+
+// LD V0, 0; LD I, 0x300; loop: CALL sub; ADD V0, 1; LD [I], V0; JP loop;
+// sub: LD V1, 5; RET
+var rom = []byte{0x60, 0x00, 0xA3, 0x00, 0x22, 0x0C, 0x70, 0x01, 0xF0, 0x55, 0x12, 0x04, 0x61, 0x05, 0x00, 0xEE}
+
+const sourceMap = `0x200 game.8o:1
+0x202 game.8o:2
+0x204 game.8o:3
+0x206 game.8o:4
+0x208 game.8o:5
+0x20A game.8o:6
+0x20C game.8o:8
+0x20E game.8o:9
+`
+
+const symbols = `loop = 0x204
+: sub 0x20C
+`
+
+type message struct {
+	Type    string          `json:"type"`
+	Command string          `json:"command"`
+	Event   string          `json:"event"`
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Body    json.RawMessage `json:"body"`
+}
+
+type client struct {
+	t      *testing.T
+	conn   net.Conn
+	r      *textproto.Reader
+	seq    int
+	events []message
+}
+
+func (c *client) read() message {
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	header, err := c.r.ReadMIMEHeader()
+
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	length, _ := strconv.Atoi(header.Get("Content-Length"))
+	data := make([]byte, length)
+
+	if _, err := io.ReadFull(c.r.R, data); err != nil {
+		c.t.Fatal(err)
+	}
+
+	var m message
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		c.t.Fatal(err)
+	}
+
+	return m
+}
+
+// request sends a request and decodes the body of its response into body,
+// events received meanwhile are queued.
+func (c *client) request(command string, args any, body any) {
+	c.seq++
+	data, _ := json.Marshal(map[string]any{"seq": c.seq, "type": "request", "command": command, "arguments": args})
+	fmt.Fprintf(c.conn, "Content-Length: %d\r\n\r\n%s", len(data), data)
+
+	for {
+		m := c.read()
+
+		if m.Type == "event" {
+			c.events = append(c.events, m)
+			continue
+		}
+
+		if !m.Success {
+			c.t.Fatalf("%s: %s", command, m.Message)
+		}
+
+		if body != nil {
+			json.Unmarshal(m.Body, body)
+		}
+
+		return
+	}
+}
+
+// event waits for an event and returns its body.
+func (c *client) event(name string) map[string]any {
+	for {
+		var m message
+
+		if len(c.events) > 0 {
+			m, c.events = c.events[0], c.events[1:]
+		} else {
+			m = c.read()
+		}
+
+		if m.Type == "event" && m.Event == name {
+			body := map[string]any{}
+			json.Unmarshal(m.Body, &body)
+
+			return body
+		}
+	}
+}
+
+func (c *client) stopped(reason string) {
+	if body := c.event("stopped"); body["reason"] != reason {
+		c.t.Fatalf("stopped reason = %v; expected %s", body["reason"], reason)
+	}
+}
+
+// lines returns the source lines of the stack frames.
+func (c *client) lines() []int {
+	var body struct {
+		StackFrames []struct {
+			Line int `json:"line"`
+		} `json:"stackFrames"`
+	}
+
+	c.request("stackTrace", map[string]any{"threadId": 1}, &body)
+	lines := []int{}
+
+	for _, f := range body.StackFrames {
+		lines = append(lines, f.Line)
+	}
+
+	return lines
+}
+
+func (c *client) register(name string) string {
+	var body struct {
+		Variables []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"variables"`
+	}
+
+	c.request("variables", map[string]any{"variablesReference": dap.REGISTERS_REFERENCE}, &body)
+
+	for _, v := range body.Variables {
+		if v.Name == name {
+			return v.Value
+		}
+	}
+
+	c.t.Fatalf("no register %s", name)
+
+	return ""
+}
+
+func serve(t *testing.T) *client {
+	s, err := dap.Listen("localhost:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Load = func(rom []byte, title string) error {
+		c := cpu.NewCpu()
+		c.LoadROM(rom)
+		s.CPU = &c
+
+		return nil
+	}
+
+	done := make(chan bool)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			if !s.Poll() || s.CPU == nil {
+				time.Sleep(time.Millisecond)
+				continue
+			}
+
+			for i := 0; i < cpu.SPEED; i++ {
+				if err := s.CPU.Run(); err != nil {
+					s.Stopped(err)
+					break
+				}
+			}
+		}
+	}()
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		close(done)
+		s.Close()
+	})
+
+	return &client{t: t, conn: conn, r: textproto.NewReader(bufio.NewReader(conn))}
+}
+
+func launch(t *testing.T) (*client, string) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "game.ch8"), rom, 0644)
+	os.WriteFile(filepath.Join(dir, "game.map"), []byte(sourceMap), 0644)
+	os.WriteFile(filepath.Join(dir, "game.sym"), []byte(symbols), 0644)
+
+	c := serve(t)
+	c.request("initialize", map[string]any{"adapterID": "chip8"}, nil)
+	c.request("launch", map[string]any{
+		"rom":       filepath.Join(dir, "game.ch8"),
+		"sourceMap": filepath.Join(dir, "game.map"),
+		"symbols":   []string{filepath.Join(dir, "game.sym")},
+	}, nil)
+	c.event("initialized")
+
+	return c, filepath.Join(dir, "game.8o")
+}
+
+func setBreakpoints(c *client, path string, lines ...int) {
+	breakpoints := []map[string]any{}
+
+	for _, l := range lines {
+		breakpoints = append(breakpoints, map[string]any{"line": l})
+	}
+
+	var body struct {
+		Breakpoints []struct {
+			Verified bool `json:"verified"`
+		} `json:"breakpoints"`
+	}
+
+	c.request("setBreakpoints", map[string]any{"source": map[string]any{"path": path}, "breakpoints": breakpoints}, &body)
+
+	for i, b := range body.Breakpoints {
+		if !b.Verified {
+			c.t.Errorf("breakpoint at line %d not verified", lines[i])
+		}
+	}
+}
+
+func TestBreakpointsAndStepping(t *testing.T) {
+	c, source := launch(t)
+
+	setBreakpoints(c, source, 4)
+	c.request("configurationDone", nil, nil)
+	c.stopped("breakpoint")
+
+	if lines := c.lines(); lines[0] != 4 {
+		t.Errorf("lines = %v; expected to stop at line 4", lines)
+	}
+
+	if v := c.register("V1"); v != "0x05" {
+		t.Errorf("V1 = %s; expected 0x05", v)
+	}
+
+	c.request("next", map[string]any{"threadId": 1}, nil)
+	c.stopped("step")
+
+	if lines := c.lines(); lines[0] != 5 {
+		t.Errorf("lines = %v; expected to step to line 5", lines)
+	}
+
+	c.request("continue", map[string]any{"threadId": 1}, nil)
+	c.stopped("breakpoint")
+
+	if v := c.register("V0"); v != "0x01" {
+		t.Errorf("V0 = %s; expected 0x01", v)
+	}
+
+	// Into the subroutine and back out
+	setBreakpoints(c, source, 3)
+	c.request("continue", map[string]any{"threadId": 1}, nil)
+	c.stopped("breakpoint")
+
+	c.request("stepIn", map[string]any{"threadId": 1}, nil)
+	c.stopped("step")
+
+	if lines := c.lines(); len(lines) != 2 || lines[0] != 8 || lines[1] != 3 {
+		t.Errorf("lines = %v; expected [8 3]", lines)
+	}
+
+	var trace struct {
+		StackFrames []struct {
+			Name string `json:"name"`
+		} `json:"stackFrames"`
+	}
+
+	c.request("stackTrace", map[string]any{"threadId": 1}, &trace)
+
+	if names := trace.StackFrames; len(names) != 2 || names[0].Name != "sub (020C)" || names[1].Name != "loop (0204)" {
+		t.Errorf("frames = %v; expected sub (020C), loop (0204)", names)
+	}
+
+	c.request("stepOut", map[string]any{"threadId": 1}, nil)
+	c.stopped("step")
+
+	if lines := c.lines(); len(lines) != 1 || lines[0] != 4 {
+		t.Errorf("lines = %v; expected [4]", lines)
+	}
+}
+
+func TestDisassemblyAndEvaluate(t *testing.T) {
+	c, _ := launch(t)
+	c.request("configurationDone", nil, nil)
+	c.request("pause", map[string]any{"threadId": 1}, nil)
+	c.stopped("pause")
+
+	var source struct {
+		Content string `json:"content"`
+	}
+
+	c.request("source", map[string]any{"sourceReference": dap.DISASSEMBLY_REFERENCE}, &source)
+
+	if !strings.Contains(source.Content, "loop:\n0204  220C  2NNN CALL addr  ; sub\n") {
+		t.Errorf("disassembly = %q", source.Content)
+	}
+
+	c.request("setVariable", map[string]any{"variablesReference": dap.REGISTERS_REFERENCE, "name": "VA", "value": "0x10"}, nil)
+
+	var result struct {
+		Result string `json:"result"`
+	}
+
+	c.request("evaluate", map[string]any{"expression": "VA + 1"}, &result)
+
+	if result.Result != "0x11 (17)" {
+		t.Errorf("result = %q; expected 0x11 (17)", result.Result)
+	}
+
+	c.request("evaluate", map[string]any{"expression": "sub"}, &result)
+
+	if result.Result != "0x020C" {
+		t.Errorf("result = %q; expected 0x020C", result.Result)
+	}
+
+	// Line 9 is the first instruction of sub, after the loop: and sub:
+	// headers
+	disassembly := map[string]any{"sourceReference": dap.DISASSEMBLY_REFERENCE}
+	c.request("setBreakpoints", map[string]any{"source": disassembly, "breakpoints": []map[string]any{{"line": 9}}}, nil)
+	c.request("continue", map[string]any{"threadId": 1}, nil)
+	c.stopped("breakpoint")
+	c.request("evaluate", map[string]any{"expression": "PC"}, &result)
+
+	if result.Result != "0x20C (524)" {
+		t.Errorf("PC = %q; expected 0x20C (524)", result.Result)
+	}
+}
+
+func TestListenLoopback(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:0", "[::]:0", "192.0.2.1:0", "example.com:0"} {
+		if s, err := dap.Listen(addr); err == nil {
+			s.Close()
+			t.Errorf("Listen(%q) succeeded; expected an error", addr)
+		}
+	}
+
+	s, err := dap.Listen(":0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer s.Close()
+
+	if ip := s.Addr().(*net.TCPAddr).IP; !ip.IsLoopback() {
+		t.Errorf("Listen(\":0\") = %s; expected a loopback address", ip)
+	}
+}
@@ -0,0 +1,154 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// request is a message sent by the client.
+type request struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type response struct {
+	Seq        int    `json:"seq"`
+	Type       string `json:"type"`
+	RequestSeq int    `json:"request_seq"`
+	Success    bool   `json:"success"`
+	Command    string `json:"command"`
+	Message    string `json:"message,omitempty"`
+	Body       any    `json:"body,omitempty"`
+}
+
+type eventMessage struct {
+	Seq   int    `json:"seq"`
+	Type  string `json:"type"`
+	Event string `json:"event"`
+	Body  any    `json:"body,omitempty"`
+}
+
+// conn reads and writes messages framed by a Content-Length header.
+type conn struct {
+	rw  io.ReadWriteCloser
+	r   *textproto.Reader
+	mu  sync.Mutex
+	seq int
+}
+
+func newConn(rw net.Conn) *conn {
+	return &conn{rw: rw, r: textproto.NewReader(bufio.NewReader(rw))}
+}
+
+// Read returns the next request of the client.
+func (c *conn) Read() (*request, error) {
+	header, err := c.r.ReadMIMEHeader()
+
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+
+	data := make([]byte, length)
+
+	if _, err := io.ReadFull(c.r.R, data); err != nil {
+		return nil, err
+	}
+
+	req := &request{}
+
+	return req, json.Unmarshal(data, req)
+}
+
+// write sends a response or an event, numbering it.
+func (c *conn) write(m any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+
+	switch m := m.(type) {
+	case *response:
+		m.Seq = c.seq
+		m.Type = "response"
+	case *eventMessage:
+		m.Seq = c.seq
+		m.Type = "event"
+	}
+
+	data, err := json.Marshal(m)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(c.rw, "Content-Length: %d\r\n\r\n%s", len(data), data)
+
+	return err
+}
+
+func (c *conn) event(name string, body any) error {
+	return c.write(&eventMessage{Event: name, Body: body})
+}
+
+func (c *conn) Close() error {
+	return c.rw.Close()
+}
+
+// Bodies and arguments of the requests used, named as in the
+// specification.
+
+type source struct {
+	Name            string `json:"name,omitempty"`
+	Path            string `json:"path,omitempty"`
+	SourceReference int    `json:"sourceReference,omitempty"`
+}
+
+type sourceBreakpoint struct {
+	Line         int    `json:"line"`
+	Condition    string `json:"condition,omitempty"`
+	HitCondition string `json:"hitCondition,omitempty"`
+}
+
+type breakpoint struct {
+	ID       int     `json:"id,omitempty"`
+	Verified bool    `json:"verified"`
+	Message  string  `json:"message,omitempty"`
+	Source   *source `json:"source,omitempty"`
+	Line     int     `json:"line,omitempty"`
+}
+
+type stackFrame struct {
+	ID                    int     `json:"id"`
+	Name                  string  `json:"name"`
+	Source                *source `json:"source,omitempty"`
+	Line                  int     `json:"line"`
+	Column                int     `json:"column"`
+	InstructionPointerRef string  `json:"instructionPointerReference,omitempty"`
+	PresentationHint      string  `json:"presentationHint,omitempty"`
+}
+
+type variable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+type scope struct {
+	Name               string `json:"name"`
+	VariablesReference int    `json:"variablesReference"`
+	Expensive          bool   `json:"expensive"`
+}
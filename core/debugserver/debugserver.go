@@ -0,0 +1,178 @@
+// Package debugserver is the connection handling shared by the GDB and DAP
+// servers. Clients are read on their own goroutines and their messages
+// handled by the goroutine calling Poll, the frontend loop, so the machine
+// does not need locks. One client is served at a time.
+package debugserver
+
+import (
+	"fmt"
+	"net"
+)
+
+// Conn is a client connection, Read returns its next message and fails
+// once it is closed.
+type Conn[M any] interface {
+	comparable
+	Read() (M, error)
+	Close() error
+}
+
+// Handlers are called by Poll for the attached client.
+type Handlers[C any, M any] struct {
+	Attach func(c C) // Optional
+	Handle func(m M)
+	Detach func() // Removes the state of the client, e.g. its breakpoints
+}
+
+// event is sent by the connection goroutines to the machine goroutine.
+type event[C any, M any] struct {
+	conn    C
+	message M
+	opened  bool
+	closed  bool
+}
+
+// Server accepts clients and queues their messages for Poll.
+type Server[C Conn[M], M any] struct {
+	Client C    // The attached client, valid while Attached
+	Halted bool // The client holds the machine
+
+	attached bool
+	listener net.Listener
+	events   chan event[C, M]
+	newConn  func(net.Conn) C
+	handlers Handlers[C, M]
+}
+
+// Loopback checks that addr only listens on this machine, debuggers read
+// and write the whole machine. A missing host is 127.0.0.1, e.g. ":1234".
+func Loopback(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+
+	if err != nil {
+		return "", err
+	}
+
+	if host == "" {
+		return net.JoinHostPort("127.0.0.1", port), nil
+	}
+
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return "", fmt.Errorf("%s is not a loopback address", host)
+	}
+
+	return addr, nil
+}
+
+// Listen starts a server on addr, newConn wraps the accepted connections.
+func Listen[C Conn[M], M any](addr string, newConn func(net.Conn) C, handlers Handlers[C, M]) (*Server[C, M], error) {
+	l, err := net.Listen("tcp", addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server[C, M]{
+		listener: l,
+		events:   make(chan event[C, M], 16),
+		newConn:  newConn,
+		handlers: handlers,
+	}
+
+	go s.accept()
+
+	return s, nil
+}
+
+func (s *Server[C, M]) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+func (s *Server[C, M]) Close() error {
+	return s.listener.Close()
+}
+
+func (s *Server[C, M]) accept() {
+	for {
+		c, err := s.listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		go s.read(s.newConn(c))
+	}
+}
+
+func (s *Server[C, M]) read(c C) {
+	s.events <- event[C, M]{conn: c, opened: true}
+
+	defer func() {
+		c.Close()
+		s.events <- event[C, M]{conn: c, closed: true}
+	}()
+
+	for {
+		m, err := c.Read()
+
+		if err != nil {
+			return
+		}
+
+		s.events <- event[C, M]{conn: c, message: m}
+	}
+}
+
+// Attached reports whether a client is connected.
+func (s *Server[C, M]) Attached() bool {
+	return s.attached
+}
+
+// Poll handles the pending messages of the client and reports whether the
+// machine can run, it does not while the client has it halted.
+func (s *Server[C, M]) Poll() bool {
+	for {
+		select {
+		case e := <-s.events:
+			s.handle(e)
+		default:
+			return !s.Halted
+		}
+	}
+}
+
+func (s *Server[C, M]) handle(e event[C, M]) {
+	switch {
+	case e.opened && !s.attached:
+		s.Client = e.conn
+		s.attached = true
+
+		if s.handlers.Attach != nil {
+			s.handlers.Attach(e.conn)
+		}
+	case !s.attached || e.conn != s.Client:
+		// A second client, refused while the first one is attached
+		if e.opened {
+			e.conn.Close()
+		}
+	case e.closed:
+		s.Detach()
+	default:
+		s.handlers.Handle(e.message)
+	}
+}
+
+// Detach drops the client and resumes the machine, the client connection
+// is not closed.
+func (s *Server[C, M]) Detach() {
+	if !s.attached {
+		return
+	}
+
+	s.handlers.Detach()
+
+	var none C
+	s.Client = none
+	s.attached = false
+	s.Halted = false
+}
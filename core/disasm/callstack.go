@@ -0,0 +1,53 @@
+package disasm
+
+import (
+	"fmt"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+)
+
+// Call is a frame of the call stack.
+type Call struct {
+	Depth  int    // 1 for the first call
+	Caller uint16 // Address of the call instruction
+	Callee uint16 // Subroutine called, 0 when the caller is not a 2NNN
+	Label  string // Name of the callee, empty without symbols
+}
+
+func (c Call) String() string {
+	callee := "????"
+
+	if c.Callee != 0 {
+		callee = fmt.Sprintf("%04X", c.Callee)
+	}
+
+	if c.Label != "" {
+		callee += " " + c.Label
+	}
+
+	return fmt.Sprintf("#%d %04X > %s", c.Depth, c.Caller, callee)
+}
+
+// CallStack returns the calls on the stack of c, the last call first. The
+// callees are read from the call instructions, which self-modifying ROMs
+// may have changed since.
+func CallStack(c *cpu.CPU, symbols *Symbols) []Call {
+	stack := c.Stack()
+	mmu := c.Memory()
+	calls := []Call{}
+
+	for _, f := range stack.Frames() {
+		call := Call{Depth: f.Depth, Caller: f.Caller()}
+
+		if int(call.Caller)+1 < mmu.Size() {
+			if op := uint16(mmu.Peek(uint32(call.Caller)))<<8 | uint16(mmu.Peek(uint32(call.Caller)+1)); op&0xF000 == 0x2000 {
+				call.Callee = op & 0x0FFF
+				call.Label = symbols.Name(call.Callee)
+			}
+		}
+
+		calls = append(calls, call)
+	}
+
+	return calls
+}
@@ -0,0 +1,36 @@
+package disasm_test
+
+import (
+	"testing"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/disasm"
+)
+
+func TestCallStack(t *testing.T) {
+	// CALL draw; JP 0x200; draw: CALL pixel; RET; pixel: RET
+	c := cpu.NewCpu()
+	c.LoadROM([]byte{0x22, 0x04, 0x12, 0x00, 0x22, 0x08, 0x00, 0xEE, 0x00, 0xEE})
+
+	for i := 0; i < 2; i++ {
+		if err := c.Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	symbols := disasm.NewSymbols()
+	symbols.AddLabel("draw", 0x204)
+
+	calls := disasm.CallStack(&c, symbols)
+	expected := []string{"#2 0204 > 0208 draw+4", "#1 0200 > 0204 draw"}
+
+	if len(calls) != len(expected) {
+		t.Fatalf("CallStack() = %v; expected %v", calls, expected)
+	}
+
+	for i, call := range calls {
+		if call.String() != expected[i] {
+			t.Errorf("CallStack()[%d] = %q; expected %q", i, call, expected[i])
+		}
+	}
+}
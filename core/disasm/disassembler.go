@@ -0,0 +1,188 @@
+// Package disasm decodes CHIP-8, SUPER-CHIP and MEGA-CHIP instructions and
+// names addresses with the symbols of a ROM, for the debugging tools of the
+// frontend and the command line.
+package disasm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+)
+
+func Disassemble(bytes []byte) []string {
+	instructions := []string{}
+
+	for i := 0; i+1 < len(bytes); i += 2 {
+		hb := uint16(bytes[i])
+		lb := uint16(bytes[i+1])
+
+		instruction := (hb << 8) | lb
+
+		if m := Mnemonic(instruction); m != "" {
+			instructions = append(instructions, fmt.Sprintf("0x%.4X - %s\n", instruction, m))
+		}
+	}
+
+	return instructions
+}
+
+// Listing disassembles a ROM loaded at start with addresses, a header
+// before every label and the label of NNN operands as comments:
+//
+//	draw:
+//	0204  A20A  ANNN LD I, addr  ; sprite
+func Listing(rom []byte, start uint16, symbols *Symbols) []string {
+	lines := []string{}
+
+	for i := 0; i < len(rom); i += 2 {
+		addr := start + uint16(i)
+		instruction := uint16(rom[i]) << 8
+
+		if i+1 < len(rom) {
+			instruction |= uint16(rom[i+1])
+		}
+
+		if label, ok := symbols.Label(addr); ok {
+			lines = append(lines, label+":\n")
+		}
+
+		line := fmt.Sprintf("%04X  %04X  %s%s", addr, instruction, Mnemonic(instruction), symbols.Comment(instruction))
+		lines = append(lines, strings.TrimSpace(line)+"\n")
+	}
+
+	return lines
+}
+
+// Mnemonic returns the description of a single instruction, or an empty
+// string when it is not a known CHIP-8 or SUPER-CHIP instruction.
+func Mnemonic(instruction uint16) string {
+	opcode := cpu.NewOpcode(instruction)
+
+	switch opcode.Instruction {
+	case 0x0000:
+		if opcode.RegisterX == 0x0 && opcode.RegisterY == 0xC {
+			return "00CN (SCROLL-DOWN N)"
+		}
+
+		if opcode.RegisterX == 0x0 && opcode.RegisterY == 0xD {
+			return "00DN (SCROLL-UP N)"
+		}
+
+		switch opcode.NNN {
+		case 0x0E0:
+			return "00E0 CLS"
+
+		case 0x0EE:
+			return "00EE RET"
+
+		case 0x0FE:
+			return "00FE (LORES)"
+
+		case 0x0FF:
+			return "00FF (HIRES)"
+
+		case 0x0FB:
+			return "00FB (SCROLL-RIGHT)"
+
+		case 0x0FC:
+			return "00FC (SCROLL-LEFT)"
+
+		case 0x0FD:
+			return "00FD (EXIT)"
+
+		default:
+			return "0NNN JP addr"
+		}
+	case 0x1000:
+		return "1NNN JP addr"
+	case 0x2000:
+		return "2NNN CALL addr"
+	case 0x3000:
+		return "3XKK SE Vx, byte"
+	case 0x4000:
+		return "4XKK SNE Vx, byte"
+	case 0x5000:
+		if opcode.N == 0x0 {
+			return "5XY0 SE Vx, Vy"
+		}
+	case 0x6000:
+		return "6XKK LD Vx, byte"
+	case 0x7000:
+		return "7XKK ADD Vx, byte"
+	case 0x8000:
+		switch opcode.N {
+		case 0x0:
+			return "8XY0 LD Vx, Vy"
+		case 0x1:
+			return "8XY1 OR Vx, Vy"
+		case 0x2:
+			return "8XY2 AND Vx, Vy"
+		case 0x3:
+			return "8XY3 XOR Vx, Vy"
+		case 0x4:
+			return "8XY4 ADD Vx, Vy"
+		case 0x5:
+			return "8XY5 SUB Vx, Vy"
+		case 0x6:
+			return "8XY6 SHR Vx {, Vy}"
+		case 0x7:
+			return "8XY7 SUBN Vx, Vy"
+		case 0xE:
+			return "8XYE SHL Vx {, Vy}"
+		}
+	case 0x9000:
+		if opcode.N == 0x0 {
+			return "9XY0 SNE Vx, Vy"
+		}
+	case 0xA000:
+		return "ANNN LD I, addr"
+	case 0xB000:
+		return "BNNN JP V0, addr"
+	case 0xC000:
+		return "CXKK RND Vx, byte"
+	case 0xD000:
+		switch opcode.N {
+		case 0x0:
+			return "DXY0 (SPRITE Vx Vy 0)"
+		default:
+			return "DXYN DRW Vx, Vy, nibble"
+		}
+	case 0xE000:
+		switch opcode.NN {
+		case 0x9E:
+			return "EX9E SKP Vx"
+		case 0xA1:
+			return "EXA1 SKNP Vx"
+		}
+	case 0xF000:
+		switch opcode.NN {
+		case 0x07:
+			return "FX07 LD Vx, DT"
+		case 0x15:
+			return "FX15 LD DT, Vx"
+		case 0x18:
+			return "FX18 LD ST, Vx"
+		case 0x0A:
+			return "FX0A LD Vx, K"
+		case 0x1E:
+			return "FX1E ADD I, Vx"
+		case 0x29:
+			return "FX29 LD F, Vx"
+		case 0x30:
+			return "FX30 (i := BIGHEX Vx)"
+		case 0x33:
+			return "FX33 LD B, Vx"
+		case 0x55:
+			return "FX55 LD [I], Vx"
+		case 0x65:
+			return "FX65 LD Vx, [I]"
+		case 0x75:
+			return "FX75 (SAVE FLAGS Vx)"
+		case 0x85:
+			return "FX85 (LOAD FLAGS Vx)"
+		}
+	}
+
+	return ""
+}
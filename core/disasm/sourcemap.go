@@ -0,0 +1,44 @@
+package disasm
+
+import "path/filepath"
+
+// SourceLine is a line of an assembler source file.
+type SourceLine struct {
+	File string // Absolute path
+	Line int    // From 1
+}
+
+// SourceMap maps ROM addresses to the source lines they were assembled
+// from.
+type SourceMap struct {
+	lines map[uint16]SourceLine
+	addrs map[SourceLine]uint16 // First address of every line
+}
+
+func NewSourceMap() *SourceMap {
+	return &SourceMap{
+		lines: map[uint16]SourceLine{},
+		addrs: map[SourceLine]uint16{},
+	}
+}
+
+func (m *SourceMap) Add(addr uint16, file string, line int) {
+	l := SourceLine{File: filepath.Clean(file), Line: line}
+	m.lines[addr] = l
+
+	if a, ok := m.addrs[l]; !ok || addr < a {
+		m.addrs[l] = addr
+	}
+}
+
+// Line returns the source line of the instruction at addr.
+func (m *SourceMap) Line(addr uint16) (SourceLine, bool) {
+	l, ok := m.lines[addr]
+	return l, ok
+}
+
+// Addr returns the address of the first instruction of a source line.
+func (m *SourceMap) Addr(file string, line int) (uint16, bool) {
+	a, ok := m.addrs[SourceLine{File: filepath.Clean(file), Line: line}]
+	return a, ok
+}
@@ -0,0 +1,289 @@
+package disasm
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+)
+
+// SYMBOL_REACH is how far after a label addresses are named after it, e.g.
+// "draw+6".
+const SYMBOL_REACH = 0x100
+
+// Symbols are the labels, constants and source lines of a ROM.
+type Symbols struct {
+	*SourceMap
+	labels    map[string]uint16
+	names     map[uint16]string
+	constants map[string]uint16
+	sorted    []uint16 // Labelled addresses in increasing order
+}
+
+func NewSymbols() *Symbols {
+	return &Symbols{
+		SourceMap: NewSourceMap(),
+		labels:    map[string]uint16{},
+		names:     map[uint16]string{},
+		constants: map[string]uint16{},
+	}
+}
+
+// LoadSymbols reads symbol files, one entry per line and '#' comments:
+//
+//	main = 0x202       label
+//	0x202 main         label, also "main 0x202"
+//	: main 0x202       label, Octo style
+//	:const SPEED 3     constant, resolved but not used to name addresses
+//	0x202 game.8o:12   source line, as in .map files
+//
+// Addresses are hexadecimal, with or without 0x. Relative source paths
+// are relative to the symbol file.
+func LoadSymbols(paths ...string) (*Symbols, error) {
+	s := NewSymbols()
+
+	for _, path := range paths {
+		if err := s.load(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *Symbols) load(path string) error {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for n := 1; scanner.Scan(); n++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+
+		if err := s.parse(strings.Fields(text), filepath.Dir(path)); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (s *Symbols) parse(fields []string, dir string) error {
+	switch {
+	case len(fields) == 0:
+		return nil
+	case len(fields) == 3 && fields[0] == ":const":
+		value, err := strconv.ParseUint(fields[2], 0, 16)
+
+		if err != nil {
+			return fmt.Errorf("invalid constant %q", fields[2])
+		}
+
+		s.constants[fields[1]] = uint16(value)
+
+		return nil
+	case len(fields) == 3 && fields[0] == ":":
+		fields = fields[1:]
+	case len(fields) == 3 && fields[1] == "=":
+		fields = []string{fields[0], fields[2]}
+	}
+
+	if len(fields) != 2 {
+		return fmt.Errorf("unexpected %q", strings.Join(fields, " "))
+	}
+
+	addr, ok := parseAddress(fields[0])
+	name := fields[1]
+
+	if !ok {
+		addr, ok = parseAddress(fields[1])
+		name = fields[0]
+	}
+
+	if !ok {
+		return fmt.Errorf("expected an address in %q", strings.Join(fields, " "))
+	}
+
+	if colon := strings.LastIndex(name, ":"); colon > 0 {
+		line, err := strconv.Atoi(name[colon+1:])
+
+		if err != nil || line < 1 {
+			return fmt.Errorf("invalid source line %q", name)
+		}
+
+		file := name[:colon]
+
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+
+		s.SourceMap.Add(addr, file, line)
+
+		return nil
+	}
+
+	if strings.ContainsAny(name, "./\\") {
+		return fmt.Errorf("invalid label %q, source lines are file:line", name)
+	}
+
+	s.AddLabel(name, addr)
+
+	return nil
+}
+
+// parseAddress parses hexadecimal addresses, labels never start with a
+// digit so "0202" is an address and "add" a label.
+func parseAddress(s string) (uint16, bool) {
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return 0, false
+	}
+
+	addr, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "0x"), 16, 16)
+
+	return uint16(addr), err == nil
+}
+
+func (s *Symbols) AddLabel(name string, addr uint16) {
+	s.labels[name] = addr
+
+	if _, ok := s.names[addr]; !ok {
+		s.names[addr] = name
+		s.sorted = nil
+	}
+}
+
+// Label returns the label of addr, the first one loaded when there are
+// several. A nil *Symbols has no labels.
+func (s *Symbols) Label(addr uint16) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+
+	name, ok := s.names[addr]
+	return name, ok
+}
+
+// Name describes addr relative to the closest label before it, e.g.
+// "draw" or "draw+6", or returns an empty string when there is none within
+// SYMBOL_REACH bytes. A nil *Symbols has no names.
+func (s *Symbols) Name(addr uint16) string {
+	if s == nil || len(s.names) == 0 {
+		return ""
+	}
+
+	if s.sorted == nil {
+		for a := range s.names {
+			s.sorted = append(s.sorted, a)
+		}
+
+		sort.Slice(s.sorted, func(i, j int) bool { return s.sorted[i] < s.sorted[j] })
+	}
+
+	i := sort.Search(len(s.sorted), func(i int) bool { return s.sorted[i] > addr }) - 1
+
+	if i < 0 || addr-s.sorted[i] >= SYMBOL_REACH {
+		return ""
+	}
+
+	if addr == s.sorted[i] {
+		return s.names[addr]
+	}
+
+	return fmt.Sprintf("%s+%d", s.names[s.sorted[i]], addr-s.sorted[i])
+}
+
+// Resolve returns the address of a label or constant with an optional
+// decimal or hexadecimal offset ("draw", "draw+6", "draw+0x10"), a source
+// line ("game.8o:12", matched by file name when the path is relative) or a
+// hexadecimal address. A nil *Symbols only resolves addresses.
+func (s *Symbols) Resolve(expr string) (uint16, error) {
+	if addr, ok := parseAddress(expr); ok {
+		return addr, nil
+	}
+
+	if s == nil {
+		return 0, fmt.Errorf("invalid address %q", expr)
+	}
+
+	if colon := strings.LastIndex(expr, ":"); colon > 0 {
+		if line, err := strconv.Atoi(expr[colon+1:]); err == nil {
+			if addr, ok := s.lineAddr(expr[:colon], line); ok {
+				return addr, nil
+			}
+
+			return 0, fmt.Errorf("no instruction at %q", expr)
+		}
+	}
+
+	name, offset, _ := strings.Cut(expr, "+")
+	addr, ok := s.labels[name]
+
+	if !ok {
+		if addr, ok = s.constants[name]; !ok {
+			return 0, fmt.Errorf("unknown label %q", name)
+		}
+	}
+
+	if offset != "" {
+		n, err := strconv.ParseUint(offset, 0, 16)
+
+		if err != nil {
+			return 0, fmt.Errorf("invalid offset %q", offset)
+		}
+
+		addr += uint16(n)
+	}
+
+	return addr, nil
+}
+
+// lineAddr finds a source line by path, or by file name for relative
+// paths.
+func (s *Symbols) lineAddr(file string, line int) (uint16, bool) {
+	if filepath.IsAbs(file) {
+		return s.SourceMap.Addr(file, line)
+	}
+
+	for l, addr := range s.addrs {
+		if l.Line == line && (filepath.Base(l.File) == file || strings.HasSuffix(l.File, string(filepath.Separator)+filepath.Clean(file))) {
+			return addr, true
+		}
+	}
+
+	return 0, false
+}
+
+// ParseBreakpoint is cpu.ParseBreakpoint with labels and source lines as
+// addresses, e.g. "draw+6" or "write sprites-sprites+7".
+func (s *Symbols) ParseBreakpoint(spec string) (*cpu.Breakpoint, error) {
+	return cpu.ParseBreakpointFunc(spec, func(expr string) (uint32, error) {
+		addr, err := s.Resolve(expr)
+		return uint32(addr), err
+	})
+}
+
+// Comment returns "  ; label" for instructions whose NNN operand is an
+// address with a name, or an empty string.
+func (s *Symbols) Comment(instruction uint16) string {
+	switch instruction & 0xF000 {
+	case 0x1000, 0x2000, 0xA000, 0xB000:
+	default:
+		return ""
+	}
+
+	if name := s.Name(instruction & 0x0FFF); name != "" {
+		return "  ; " + name
+	}
+
+	return ""
+}
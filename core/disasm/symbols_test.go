@@ -0,0 +1,112 @@
+package disasm_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/disasm"
+)
+
+const symbolFile = `# game
+main = 0x200
+0x204 draw
+sprite 0x20A
+: loop 0x206
+:const SPEED 3
+0x200 game.8o:1
+0202 game.8o:1
+0x204 lib/draw.8o:7
+`
+
+func loadSymbols(t *testing.T, content string) (*disasm.Symbols, string, error) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "game.sym")
+	os.WriteFile(path, []byte(content), 0644)
+
+	s, err := disasm.LoadSymbols(path)
+
+	return s, dir, err
+}
+
+func TestLoadSymbols(t *testing.T) {
+	s, dir, err := loadSymbols(t, symbolFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l, ok := s.Line(0x204); !ok || l.File != filepath.Join(dir, "lib", "draw.8o") || l.Line != 7 {
+		t.Errorf("Line(0x204) = %v; expected lib/draw.8o:7", l)
+	}
+
+	if addr, ok := s.Addr(filepath.Join(dir, "game.8o"), 1); !ok || addr != 0x200 {
+		t.Errorf("Addr(game.8o, 1) = 0x%X; expected 0x200", addr)
+	}
+
+	for addr, expected := range map[uint16]string{0x200: "main", 0x204: "draw", 0x206: "loop", 0x20A: "sprite", 0x20C: "sprite+2", 0x30A: ""} {
+		if name := s.Name(addr); name != expected {
+			t.Errorf("Name(0x%X) = %q; expected %q", addr, name, expected)
+		}
+	}
+
+	for _, content := range []string{"0x200 game.8o\n", "main\n", "main = loop\n"} {
+		if _, _, err := loadSymbols(t, content); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	s, _, err := loadSymbols(t, symbolFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for expr, expected := range map[string]uint16{"main": 0x200, "draw+2": 0x206, "sprite+0x10": 0x21A, "SPEED": 3, "204": 0x204, "0x2F0": 0x2F0, "game.8o:1": 0x200, "draw.8o:7": 0x204} {
+		if addr, err := s.Resolve(expr); err != nil || addr != expected {
+			t.Errorf("Resolve(%q) = 0x%X, %v; expected 0x%X", expr, addr, err, expected)
+		}
+	}
+
+	for _, expr := range []string{"missing", "draw+x", "game.8o:2"} {
+		if _, err := s.Resolve(expr); err == nil {
+			t.Errorf("Resolve(%q): expected an error", expr)
+		}
+	}
+
+	b, err := s.ParseBreakpoint("write sprite-sprite+7 hits 2")
+
+	if err != nil || b.Kind != cpu.BREAK_WRITE || b.Start != 0x20A || b.End != 0x211 || b.HitCount != 2 {
+		t.Errorf("ParseBreakpoint = %v, %v; expected write 20A-211 hits 2", b, err)
+	}
+}
+
+func TestListing(t *testing.T) {
+	s, _, err := loadSymbols(t, symbolFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rom := []byte{0x00, 0xE0, 0xA2, 0x0A, 0x22, 0x04, 0xD0, 0x15, 0x12}
+	expected := []string{
+		"main:",
+		"0200  00E0  00E0 CLS",
+		"0202  A20A  ANNN LD I, addr  ; sprite",
+		"draw:",
+		"0204  2204  2NNN CALL addr  ; draw",
+		"loop:",
+		"0206  D015  DXYN DRW Vx, Vy, nibble",
+		"0208  1200  1NNN JP addr  ; main",
+	}
+
+	listing := disasm.Listing(rom, 0x200, s)
+
+	if got := strings.TrimSpace(strings.Join(listing, "")); got != strings.Join(expected, "\n") {
+		t.Errorf("Listing =\n%s\nexpected\n%s", got, strings.Join(expected, "\n"))
+	}
+}
@@ -0,0 +1,29 @@
+package core
+
+import (
+	_ "embed"
+
+	"github.com/gaoliveira21/chip8/core/config"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var (
+	//go:embed shaders/scanlines.kage
+	scanlinesShader []byte
+
+	//go:embed shaders/crt.kage
+	crtShader []byte
+)
+
+// newFilterShader compiles the post-processing shader for filter, it
+// returns nil when no filter is selected.
+func newFilterShader(filter string) (*ebiten.Shader, error) {
+	switch filter {
+	case config.FILTER_SCANLINES:
+		return ebiten.NewShader(scanlinesShader)
+	case config.FILTER_CRT:
+		return ebiten.NewShader(crtShader)
+	}
+
+	return nil, nil
+}
@@ -42,3 +42,78 @@ var SCHIP_FontData = [...]byte{
 	0xff, 0xff, 0xc0, 0xc0, 0xff, 0xff, 0xc0, 0xc0, 0xff, 0xff, // E
 	0xff, 0xff, 0xc0, 0xc0, 0xff, 0xff, 0xc0, 0xc0, 0xc0, 0xc0, // F
 }
+
+// TEXT_FontData completes CHIP8_FontData with the letters G to Z and some
+// punctuation in the same 4x5 style, for the text of the frontend.
+var TEXT_FontData = map[rune][FONT_HEIGHT]byte{
+	'G':  {0xF0, 0x80, 0xB0, 0x90, 0xF0},
+	'H':  {0x90, 0x90, 0xF0, 0x90, 0x90},
+	'I':  {0xE0, 0x40, 0x40, 0x40, 0xE0},
+	'J':  {0x30, 0x10, 0x10, 0x90, 0xF0},
+	'K':  {0x90, 0xA0, 0xC0, 0xA0, 0x90},
+	'L':  {0x80, 0x80, 0x80, 0x80, 0xF0},
+	'M':  {0x90, 0xF0, 0xF0, 0x90, 0x90},
+	'N':  {0x90, 0xD0, 0xB0, 0x90, 0x90},
+	'O':  {0xF0, 0x90, 0x90, 0x90, 0xF0},
+	'P':  {0xF0, 0x90, 0xF0, 0x80, 0x80},
+	'Q':  {0xF0, 0x90, 0x90, 0xB0, 0xF0},
+	'R':  {0xF0, 0x90, 0xF0, 0xA0, 0x90},
+	'S':  {0xF0, 0x80, 0xF0, 0x10, 0xF0},
+	'T':  {0xE0, 0x40, 0x40, 0x40, 0x40},
+	'U':  {0x90, 0x90, 0x90, 0x90, 0xF0},
+	'V':  {0x90, 0x90, 0x90, 0x60, 0x60},
+	'W':  {0x90, 0x90, 0xF0, 0xF0, 0x90},
+	'X':  {0x90, 0x90, 0x60, 0x90, 0x90},
+	'Y':  {0x90, 0x90, 0xF0, 0x10, 0xF0},
+	'Z':  {0xF0, 0x10, 0x60, 0x80, 0xF0},
+	' ':  {0x00, 0x00, 0x00, 0x00, 0x00},
+	'.':  {0x00, 0x00, 0x00, 0x00, 0x40},
+	',':  {0x00, 0x00, 0x00, 0x40, 0x80},
+	':':  {0x00, 0x40, 0x00, 0x40, 0x00},
+	'-':  {0x00, 0x00, 0xF0, 0x00, 0x00},
+	'+':  {0x00, 0x40, 0xE0, 0x40, 0x00},
+	'=':  {0x00, 0xF0, 0x00, 0xF0, 0x00},
+	'_':  {0x00, 0x00, 0x00, 0x00, 0xF0},
+	'*':  {0x00, 0xA0, 0x40, 0xA0, 0x00},
+	'/':  {0x10, 0x20, 0x20, 0x40, 0x80},
+	'<':  {0x20, 0x40, 0x80, 0x40, 0x20},
+	'>':  {0x80, 0x40, 0x20, 0x40, 0x80},
+	'(':  {0x40, 0x80, 0x80, 0x80, 0x40},
+	')':  {0x80, 0x40, 0x40, 0x40, 0x80},
+	'[':  {0xC0, 0x80, 0x80, 0x80, 0xC0},
+	']':  {0xC0, 0x40, 0x40, 0x40, 0xC0},
+	'!':  {0x40, 0x40, 0x40, 0x00, 0x40},
+	'?':  {0xE0, 0x10, 0x60, 0x00, 0x40},
+	'\'': {0x40, 0x40, 0x00, 0x00, 0x00},
+	'"':  {0xA0, 0xA0, 0x00, 0x00, 0x00},
+	'#':  {0x50, 0xF0, 0x50, 0xF0, 0x50},
+	'%':  {0x90, 0x10, 0x20, 0x40, 0x90},
+}
+
+// Glyph returns the 4x5 sprite of r, lower case letters use the upper case
+// sprite and unknown runes a question mark.
+func Glyph(r rune) [FONT_HEIGHT]byte {
+	if r >= 'a' && r <= 'z' {
+		r -= 'a' - 'A'
+	}
+
+	var digit int
+
+	switch {
+	case r >= '0' && r <= '9':
+		digit = int(r - '0')
+	case r >= 'A' && r <= 'F':
+		digit = int(r-'A') + 0x0A
+	default:
+		if g, ok := TEXT_FontData[r]; ok {
+			return g
+		}
+
+		return TEXT_FontData['?']
+	}
+
+	var g [FONT_HEIGHT]byte
+	copy(g[:], CHIP8_FontData[digit*FONT_HEIGHT:])
+
+	return g
+}
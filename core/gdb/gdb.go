@@ -0,0 +1,463 @@
+// Package gdb is a GDB remote serial protocol stub, debuggers attach to a
+// running machine over TCP.
+package gdb
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/debugserver"
+)
+
+// message is a packet or a Ctrl-C sent by the debugger.
+type message struct {
+	packet    string
+	interrupt bool
+}
+
+type conn struct {
+	net.Conn
+	r     *bufio.Reader
+	mu    sync.Mutex
+	noAck atomic.Bool
+}
+
+func newConn(c net.Conn) *conn {
+	return &conn{Conn: c, r: bufio.NewReader(c)}
+}
+
+// Read returns the next packet or interrupt of the stream, acknowledging
+// packets and dropping those with a wrong checksum.
+func (c *conn) Read() (message, error) {
+	for {
+		b, err := c.r.ReadByte()
+
+		if err != nil {
+			return message{}, err
+		}
+
+		switch b {
+		case 0x03:
+			return message{interrupt: true}, nil
+		case '$':
+			data, err := c.r.ReadString('#')
+
+			if err != nil {
+				return message{}, err
+			}
+
+			checksum := make([]byte, 2)
+
+			if _, err := io.ReadFull(c.r, checksum); err != nil {
+				return message{}, err
+			}
+
+			data = data[:len(data)-1]
+			var sum byte
+
+			for i := 0; i < len(data); i++ {
+				sum += data[i]
+			}
+
+			if expected, err := strconv.ParseUint(string(checksum), 16, 8); err != nil || byte(expected) != sum {
+				c.ack('-')
+				continue
+			}
+
+			if !c.noAck.Load() {
+				c.ack('+')
+			}
+
+			return message{packet: data}, nil
+		}
+	}
+}
+
+// send writes a packet, framed with its checksum.
+func (c *conn) send(data string) error {
+	var sum byte
+
+	for i := 0; i < len(data); i++ {
+		sum += data[i]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := fmt.Fprintf(c, "$%s#%02x", data, sum)
+
+	return err
+}
+
+func (c *conn) ack(b byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Write([]byte{b})
+}
+
+// Server serves one debugger at a time. The machine is only touched by the
+// goroutine calling Poll and Stopped, the frontend loop, so it does not
+// need locks.
+type Server struct {
+	CPU *cpu.CPU
+
+	*debugserver.Server[*conn, message]
+	added map[string]int // Breakpoint IDs added by the debugger, by Z packet
+}
+
+// Listen starts a server on addr, e.g. "localhost:1234" or ":1234", which
+// must be a loopback address.
+func Listen(addr string, c *cpu.CPU) (*Server, error) {
+	addr, err := debugserver.Loopback(addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{CPU: c, added: map[string]int{}}
+
+	s.Server, err = debugserver.Listen(addr, newConn, debugserver.Handlers[*conn, message]{
+		Attach: func(*conn) { s.Halted = true },
+		Handle: s.handle,
+		Detach: s.detach,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// SetCPU serves c, the frontend calls it when the machine is replaced.
+func (s *Server) SetCPU(c *cpu.CPU) {
+	s.CPU = c
+}
+
+// Poll handles the pending requests of the debugger and reports whether the
+// machine can run, it does not while the debugger has it halted. A nil
+// server always lets it run.
+func (s *Server) Poll() bool {
+	return s == nil || s.Server.Poll()
+}
+
+// Stopped reports an error returned by CPU.Run to the debugger and halts
+// the machine, it returns false for nil errors and when no debugger is
+// attached.
+func (s *Server) Stopped(err error) bool {
+	if err == nil || s == nil || !s.Attached() {
+		return false
+	}
+
+	s.Halted = true
+	s.Client.send(stopReply(err))
+
+	return true
+}
+
+func stopReply(err error) string {
+	var b *cpu.BreakError
+
+	switch {
+	case errors.As(err, &b) && b.Breakpoint.Kind == cpu.BREAK_WRITE:
+		return fmt.Sprintf("T05watch:%x;", b.Addr)
+	case errors.As(err, &b) && b.Breakpoint.Kind == cpu.BREAK_PC:
+		return "T05swbreak:;"
+	case errors.As(err, &b):
+		return "S05"
+	case err != nil:
+		return "S04" // SIGILL
+	}
+
+	return "S05"
+}
+
+func (s *Server) handle(m message) {
+	switch {
+	case m.interrupt:
+		if !s.Halted {
+			s.Halted = true
+			s.Client.send("S02") // SIGINT
+		}
+	default:
+		if reply, ok := s.reply(m.packet); ok {
+			s.Client.send(reply)
+		}
+	}
+}
+
+// detach removes the breakpoints of the debugger.
+func (s *Server) detach() {
+	for _, id := range s.added {
+		s.CPU.Breakpoints.Remove(id)
+	}
+
+	s.added = map[string]int{}
+}
+
+// reply handles a packet and returns the reply, ok is false for packets
+// answered later (continue) or not at all (kill).
+func (s *Server) reply(p string) (reply string, ok bool) {
+	switch {
+	case p == "?":
+		return "S05", true
+	case strings.HasPrefix(p, "qSupported"):
+		return "PacketSize=4000;qXfer:features:read+;swbreak+;QStartNoAckMode+", true
+	case p == "QStartNoAckMode":
+		s.Client.noAck.Store(true)
+		return "OK", true
+	case strings.HasPrefix(p, "qXfer:features:read:target.xml:"):
+		return xfer(targetXML, strings.TrimPrefix(p, "qXfer:features:read:target.xml:")), true
+	case p == "qAttached":
+		return "1", true
+	case p == "qC":
+		return "QC1", true
+	case p == "qfThreadInfo":
+		return "m1", true
+	case p == "qsThreadInfo":
+		return "l", true
+	case strings.HasPrefix(p, "H"), strings.HasPrefix(p, "T"):
+		return "OK", true
+	case p == "g":
+		return s.readRegisters(), true
+	case strings.HasPrefix(p, "G"):
+		return s.writeRegisters(p[1:]), true
+	case strings.HasPrefix(p, "p"):
+		return s.readRegister(p[1:]), true
+	case strings.HasPrefix(p, "P"):
+		return s.writeRegister(p[1:]), true
+	case strings.HasPrefix(p, "m"):
+		return s.readMemory(p[1:]), true
+	case strings.HasPrefix(p, "M"):
+		return s.writeMemory(p[1:]), true
+	case strings.HasPrefix(p, "Z"), strings.HasPrefix(p, "z"):
+		return s.breakpoint(p), true
+	case p == "vCont?":
+		return "vCont;c;s", true
+	case p == "c" || p == "vCont;c" || strings.HasPrefix(p, "vCont;c:"):
+		s.Halted = false
+		return "", false
+	case p == "s" || strings.HasPrefix(p, "vCont;s"):
+		return s.step(), true
+	case p == "D" || strings.HasPrefix(p, "D;"):
+		s.Client.send("OK")
+		s.Client.Close()
+		s.Detach()
+		return "", false
+	case p == "k":
+		s.Client.Close()
+		s.Detach()
+		return "", false
+	}
+
+	return "", true
+}
+
+// xfer returns the part of data requested by "offset,length".
+func xfer(data string, args string) string {
+	offset, length, err := parseRange(args)
+
+	if err != nil {
+		return "E01"
+	}
+
+	if offset >= len(data) {
+		return "l"
+	}
+
+	end := min(len(data), offset+length)
+
+	if end == len(data) {
+		return "l" + data[offset:end]
+	}
+
+	return "m" + data[offset:end]
+}
+
+// parseRange parses "addr,length" in hexadecimal.
+func parseRange(s string) (int, int, error) {
+	a, l, found := strings.Cut(s, ",")
+
+	if !found {
+		return 0, 0, errors.New("expected addr,length")
+	}
+
+	addr, err := strconv.ParseUint(a, 16, 32)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	length, err := strconv.ParseUint(l, 16, 32)
+
+	return int(addr), int(length), err
+}
+
+func (s *Server) readRegisters() string {
+	var b strings.Builder
+
+	for _, r := range registers {
+		b.WriteString(encode(r.get(s.CPU), r.size))
+	}
+
+	return b.String()
+}
+
+func (s *Server) writeRegisters(data string) string {
+	for _, r := range registers {
+		if len(data) < 2*r.size {
+			return "E01"
+		}
+
+		value, err := decode(data[:2*r.size], r.size)
+
+		if err != nil {
+			return "E01"
+		}
+
+		r.set(s.CPU, value)
+		data = data[2*r.size:]
+	}
+
+	return "OK"
+}
+
+func (s *Server) readRegister(arg string) string {
+	n, err := strconv.ParseUint(arg, 16, 8)
+
+	if err != nil || int(n) >= len(registers) {
+		return "E01"
+	}
+
+	r := registers[n]
+
+	return encode(r.get(s.CPU), r.size)
+}
+
+func (s *Server) writeRegister(arg string) string {
+	index, data, _ := strings.Cut(arg, "=")
+	n, err := strconv.ParseUint(index, 16, 8)
+
+	if err != nil || int(n) >= len(registers) {
+		return "E01"
+	}
+
+	r := registers[n]
+	value, err := decode(data, r.size)
+
+	if err != nil {
+		return "E01"
+	}
+
+	r.set(s.CPU, value)
+
+	return "OK"
+}
+
+func (s *Server) readMemory(arg string) string {
+	addr, length, err := parseRange(arg)
+	mmu := s.CPU.Memory()
+
+	if err != nil || addr+length > mmu.Size() {
+		return "E01"
+	}
+
+	data := make([]byte, length)
+
+	for i := range data {
+		data[i] = mmu.Peek(uint32(addr + i))
+	}
+
+	return hex.EncodeToString(data)
+}
+
+func (s *Server) writeMemory(arg string) string {
+	r, payload, _ := strings.Cut(arg, ":")
+	addr, length, err := parseRange(r)
+	mmu := s.CPU.Memory()
+
+	if err != nil || addr+length > mmu.Size() {
+		return "E01"
+	}
+
+	data, err := hex.DecodeString(payload)
+
+	if err != nil || len(data) != length {
+		return "E01"
+	}
+
+	for i, b := range data {
+		mmu.Store(uint32(addr+i), b)
+	}
+
+	return "OK"
+}
+
+// breakpoint handles Z and z packets for software breakpoints (0) and
+// write watchpoints (2).
+func (s *Server) breakpoint(p string) string {
+	fields := strings.Split(p[1:], ",")
+
+	if len(fields) < 3 || (fields[0] != "0" && fields[0] != "2") {
+		return ""
+	}
+
+	addr, length, err := parseRange(fields[1] + "," + fields[2])
+
+	if err != nil {
+		return "E01"
+	}
+
+	key := strings.Join(fields[:3], ",")
+
+	if p[0] == 'z' {
+		if id, ok := s.added[key]; ok {
+			s.CPU.Breakpoints.Remove(id)
+			delete(s.added, key)
+		}
+
+		return "OK"
+	}
+
+	if _, ok := s.added[key]; ok {
+		return "OK"
+	}
+
+	if s.CPU.Breakpoints == nil {
+		s.CPU.Breakpoints = &cpu.Breakpoints{}
+	}
+
+	b := &cpu.Breakpoint{Kind: cpu.BREAK_PC, Start: uint32(addr), End: uint32(addr)}
+
+	if fields[0] == "2" {
+		b.Kind = cpu.BREAK_WRITE
+		b.End = uint32(addr + max(length, 1) - 1)
+	}
+
+	s.added[key] = s.CPU.Breakpoints.Add(b).ID
+
+	return "OK"
+}
+
+// step runs one instruction, a breakpoint at the current instruction does
+// not count as one.
+func (s *Server) step() string {
+	err := s.CPU.Run()
+	var b *cpu.BreakError
+
+	if errors.As(err, &b) && b.Breakpoint.Kind != cpu.BREAK_WRITE {
+		err = s.CPU.Run()
+	}
+
+	return stopReply(err)
+}
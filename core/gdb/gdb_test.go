@@ -0,0 +1,188 @@
+package gdb_test
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/gdb"
+)
+
+// client is a minimal RSP client.
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (c *client) send(packet string) {
+	var sum byte
+
+	for i := 0; i < len(packet); i++ {
+		sum += packet[i]
+	}
+
+	fmt.Fprintf(c.conn, "$%s#%02x", packet, sum)
+
+	if b, err := c.r.ReadByte(); err != nil || b != '+' {
+		c.t.Fatalf("%s: ack = %q, %v; expected '+'", packet, b, err)
+	}
+}
+
+func (c *client) receive() string {
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := c.r.ReadString('$'); err != nil {
+		c.t.Fatal(err)
+	}
+
+	data, err := c.r.ReadString('#')
+
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	c.r.Discard(2)
+
+	return strings.TrimSuffix(data, "#")
+}
+
+// expect sends a packet and checks the reply.
+func (c *client) expect(packet string, reply string) {
+	c.send(packet)
+
+	if r := c.receive(); r != reply {
+		c.t.Errorf("%s = %q; expected %q", packet, r, reply)
+	}
+}
+
+// serve runs LD V0, 0; LD I, 0x300; loop: ADD V0, 1; LD [I], V0; JP loop
+// under a server, like a frontend would.
+func serve(t *testing.T) *client {
+	c := cpu.NewCpu()
+	c.LoadROM([]byte{0x60, 0x00, 0xA3, 0x00, 0x70, 0x01, 0xF0, 0x55, 0x12, 0x04})
+
+	s, err := gdb.Listen("localhost:0", &c)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan bool)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			if !s.Poll() {
+				time.Sleep(time.Millisecond)
+				continue
+			}
+
+			for i := 0; i < cpu.SPEED; i++ {
+				if err := s.CPU.Run(); err != nil {
+					s.Stopped(err)
+					break
+				}
+			}
+		}
+	}()
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		close(done)
+		s.Close()
+	})
+
+	return &client{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func TestRegistersAndMemory(t *testing.T) {
+	c := serve(t)
+
+	c.expect("?", "S05")
+	c.expect("P10=00000000", "OK")
+	c.expect("p10", "00000000")
+	c.expect("P11=0002", "OK")
+	c.expect("p11", "0002")
+	c.expect("P0=2a", "OK")
+	c.expect("p0", "2a")
+	c.expect("m200,4", "6000a300")
+	c.expect("M300,2:abcd", "OK")
+	c.expect("m300,2", "abcd")
+	c.expect("m1000,1", "E01")
+
+	c.send("g")
+
+	if g := c.receive(); len(g) != 2*25 || !strings.HasPrefix(g, "2a00") {
+		t.Errorf("g = %q; expected 25 bytes starting with V0 = 2a", g)
+	}
+
+	c.send("qXfer:features:read:target.xml:0,fff")
+
+	if xml := c.receive(); !strings.HasPrefix(xml, "l<?xml") || !strings.Contains(xml, `name="pc"`) {
+		t.Errorf("target.xml = %q", xml)
+	}
+}
+
+func TestBreakpoints(t *testing.T) {
+	c := serve(t)
+
+	// The machine runs until the debugger attaches, restart it
+	c.expect("P11=0002", "OK")
+	c.expect("Z0,206,2", "OK")
+	c.expect("c", "T05swbreak:;")
+	c.expect("p11", "0602")
+	c.expect("p0", "01")
+
+	c.expect("s", "S05")
+	c.expect("p11", "0802")
+
+	c.expect("z0,206,2", "OK")
+	c.expect("Z2,300,1", "OK")
+	c.expect("c", "T05watch:300;")
+	c.expect("m300,1", "02")
+
+	c.expect("z2,300,1", "OK")
+	c.send("c")
+	c.conn.Write([]byte{0x03})
+
+	if r := c.receive(); r != "S02" {
+		t.Errorf("interrupt = %q; expected S02", r)
+	}
+}
+
+func TestListenLoopback(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:0", "192.0.2.1:0", "example.com:0", ":0:0"} {
+		if s, err := gdb.Listen(addr, nil); err == nil {
+			s.Close()
+			t.Errorf("Listen(%q) succeeded; expected an error", addr)
+		}
+	}
+
+	s, err := gdb.Listen(":0", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer s.Close()
+
+	if ip := s.Addr().(*net.TCPAddr).IP; !ip.IsLoopback() {
+		t.Errorf("Listen(\":0\") = %s; expected a loopback address", ip)
+	}
+}
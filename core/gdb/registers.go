@@ -0,0 +1,125 @@
+package gdb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+)
+
+// register is an entry of the register file, values are sent little endian
+// as GDB expects from a little endian target.
+type register struct {
+	name string
+	size int // Bytes
+	kind string
+	get  func(c *cpu.CPU) uint32
+	set  func(c *cpu.CPU, value uint32)
+}
+
+// registers is the register file: V0 to VF, I (24 bits on MEGA-CHIP), PC,
+// SP and the delay and sound timers.
+var registers = func() []register {
+	r := []register{}
+
+	for x := uint8(0); x < 16; x++ {
+		x := x
+
+		r = append(r, register{
+			name: fmt.Sprintf("v%x", x),
+			size: 1,
+			kind: "uint8",
+			get:  func(c *cpu.CPU) uint32 { return uint32(c.V(x)) },
+			set:  func(c *cpu.CPU, value uint32) { c.SetV(x, uint8(value)) },
+		})
+	}
+
+	return append(r,
+		register{
+			name: "i",
+			size: 4,
+			kind: "data_ptr",
+			get:  func(c *cpu.CPU) uint32 { return c.Addr() },
+			set:  func(c *cpu.CPU, value uint32) { c.SetAddr(value & 0xFFFFFF) },
+		},
+		register{
+			name: "pc",
+			size: 2,
+			kind: "code_ptr",
+			get:  func(c *cpu.CPU) uint32 { return uint32(c.PC()) },
+			set:  func(c *cpu.CPU, value uint32) { c.SetPC(uint16(value)) },
+		},
+		register{
+			name: "sp",
+			size: 1,
+			kind: "uint8",
+			get:  func(c *cpu.CPU) uint32 { return uint32(c.Memory().Stack.SP) },
+			set:  func(c *cpu.CPU, value uint32) { c.Memory().Stack.SP = uint16(min(value, 16)) },
+		},
+		register{
+			name: "dt",
+			size: 1,
+			kind: "uint8",
+			get:  func(c *cpu.CPU) uint32 { return uint32(c.DelayTimer()) },
+			set:  func(c *cpu.CPU, value uint32) { c.SetDelayTimer(uint8(value)) },
+		},
+		register{
+			name: "st",
+			size: 1,
+			kind: "uint8",
+			get:  func(c *cpu.CPU) uint32 { return uint32(c.SoundTimer) },
+			set:  func(c *cpu.CPU, value uint32) { c.SoundTimer = uint8(value) },
+		},
+	)
+}()
+
+// targetXML describes the register file to GDB.
+var targetXML = func() string {
+	var b strings.Builder
+
+	b.WriteString(`<?xml version="1.0"?>
+<!DOCTYPE target SYSTEM "gdb-target.dtd">
+<target version="1.0">
+  <feature name="org.chip8.core">
+`)
+
+	for n, r := range registers {
+		fmt.Fprintf(&b, "    <reg name=\"%s\" bitsize=\"%d\" type=\"%s\" regnum=\"%d\"/>\n", r.name, 8*r.size, r.kind, n)
+	}
+
+	b.WriteString("  </feature>\n</target>\n")
+
+	return b.String()
+}()
+
+// encode returns value as size little endian bytes in hexadecimal.
+func encode(value uint32, size int) string {
+	var b strings.Builder
+
+	for i := 0; i < size; i++ {
+		fmt.Fprintf(&b, "%02x", byte(value>>(8*i)))
+	}
+
+	return b.String()
+}
+
+// decode parses size little endian bytes in hexadecimal.
+func decode(s string, size int) (uint32, error) {
+	if len(s) != 2*size {
+		return 0, fmt.Errorf("expected %d bytes, got %q", size, s)
+	}
+
+	var value uint32
+
+	for i := 0; i < size; i++ {
+		var b byte
+
+		if _, err := fmt.Sscanf(s[2*i:2*i+2], "%02x", &b); err != nil {
+			return 0, err
+		}
+
+		value |= uint32(b) << (8 * i)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,159 @@
+package graphics
+
+import (
+	"image"
+	"image/color"
+)
+
+const (
+	BLEND_NORMAL = iota
+	BLEND_25
+	BLEND_50
+	BLEND_ADD
+	BLEND_MULTIPLY
+)
+
+// ColorGraphics is the 256x192 true color display of MEGA-CHIP, sprites are
+// drawn to a back buffer that is only presented when the ROM calls Flip.
+type ColorGraphics struct {
+	front   *image.RGBA
+	back    *image.RGBA
+	indexes []byte // Palette index of each back buffer pixel, used for collisions
+	Width   int
+	Height  int
+	Alpha   uint8 // Screen alpha
+
+	// Change tracking
+	frame uint64
+	dirty bool
+}
+
+func NewColorGraphics() *ColorGraphics {
+	w := 0x100
+	h := 0xC0
+
+	return &ColorGraphics{
+		front:   image.NewRGBA(image.Rect(0, 0, w, h)),
+		back:    image.NewRGBA(image.Rect(0, 0, w, h)),
+		indexes: make([]byte, w*h),
+		Width:   w,
+		Height:  h,
+		Alpha:   0xFF,
+	}
+}
+
+// Clone returns a deep copy of g, marked dirty so it is presented again.
+func (g *ColorGraphics) Clone() *ColorGraphics {
+	c := *g
+	c.front = image.NewRGBA(g.front.Rect)
+	c.back = image.NewRGBA(g.back.Rect)
+	c.indexes = append([]byte(nil), g.indexes...)
+	c.dirty = true
+
+	copy(c.front.Pix, g.front.Pix)
+	copy(c.back.Pix, g.back.Pix)
+
+	return &c
+}
+
+// Image returns the presented frame.
+func (g *ColorGraphics) Image() *image.RGBA {
+	return g.front
+}
+
+// Dirty reports whether a new frame was presented since the last EndFrame.
+func (g *ColorGraphics) Dirty() bool {
+	return g.dirty
+}
+
+func (g *ColorGraphics) Frame() uint64 {
+	return g.frame
+}
+
+func (g *ColorGraphics) EndFrame() {
+	g.frame++
+	g.dirty = false
+}
+
+// Flip presents the back buffer and clears it for the next frame.
+func (g *ColorGraphics) Flip() {
+	g.front, g.back = g.back, g.front
+	g.dirty = true
+
+	g.Clear()
+}
+
+// Clear erases the back buffer.
+func (g *ColorGraphics) Clear() {
+	for i := range g.back.Pix {
+		g.back.Pix[i] = 0x00
+	}
+
+	for i := range g.indexes {
+		g.indexes[i] = 0x00
+	}
+}
+
+func (g *ColorGraphics) GetIndex(y int, x int) byte {
+	return g.indexes[y*g.Width+x]
+}
+
+func (g *ColorGraphics) GetPixel(y int, x int) color.RGBA {
+	return g.back.RGBAAt(x, y)
+}
+
+// SetPixel blends c over the back buffer pixel, index is the palette entry
+// of c and is kept for collision checks.
+func (g *ColorGraphics) SetPixel(y int, x int, c color.RGBA, index byte, mode int) {
+	dst := g.back.RGBAAt(x, y)
+
+	g.back.SetRGBA(x, y, blend(dst, c, mode))
+	g.indexes[y*g.Width+x] = index
+}
+
+func blend(dst color.RGBA, src color.RGBA, mode int) color.RGBA {
+	mix := func(d uint8, s uint8, a uint32) uint8 {
+		return uint8((uint32(s)*a + uint32(d)*(0xFF-a)) / 0xFF)
+	}
+
+	a := uint32(src.A)
+
+	switch mode {
+	case BLEND_25:
+		a /= 4
+	case BLEND_50:
+		a /= 2
+	case BLEND_ADD:
+		return color.RGBA{
+			uint8(min(uint32(dst.R)+uint32(src.R), 0xFF)),
+			uint8(min(uint32(dst.G)+uint32(src.G), 0xFF)),
+			uint8(min(uint32(dst.B)+uint32(src.B), 0xFF)),
+			0xFF,
+		}
+	case BLEND_MULTIPLY:
+		return color.RGBA{
+			uint8(uint32(dst.R) * uint32(src.R) / 0xFF),
+			uint8(uint32(dst.G) * uint32(src.G) / 0xFF),
+			uint8(uint32(dst.B) * uint32(src.B) / 0xFF),
+			0xFF,
+		}
+	}
+
+	return color.RGBA{mix(dst.R, src.R, a), mix(dst.G, src.G, a), mix(dst.B, src.B, a), 0xFF}
+}
+
+func (g *ColorGraphics) ScrollUp(shift uint8) {
+	s := min(int(shift), g.Height)
+	stride := g.back.Stride
+
+	copy(g.back.Pix, g.back.Pix[s*stride:])
+	copy(g.indexes, g.indexes[s*g.Width:])
+
+	for i := (g.Height - s) * stride; i < len(g.back.Pix); i++ {
+		g.back.Pix[i] = 0x00
+	}
+
+	for i := (g.Height - s) * g.Width; i < len(g.indexes); i++ {
+		g.indexes[i] = 0x00
+	}
+}
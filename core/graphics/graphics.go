@@ -1,9 +1,15 @@
 package graphics
 
+import "image"
+
 type Graphics struct {
 	display [][]byte
 	Width   int
 	Height  int
+
+	// Change tracking
+	frame uint64
+	dirty image.Rectangle
 }
 
 func newDisplay(h int, w int) [][]byte {
@@ -27,12 +33,72 @@ func NewGraphics() *Graphics {
 	}
 }
 
+// NewHighResolutionGraphics returns the fixed 128x64 display of CHIP-10.
+func NewHighResolutionGraphics() *Graphics {
+	g := NewGraphics()
+	g.EnableHighResolutionMode()
+
+	return g
+}
+
+// Clone returns a deep copy of g, marked dirty so it is redrawn once shown.
+func (g *Graphics) Clone() *Graphics {
+	c := &Graphics{
+		Width:   g.Width,
+		Height:  g.Height,
+		display: newDisplay(g.Height, g.Width),
+		frame:   g.frame,
+	}
+
+	for y := range g.display {
+		copy(c.display[y], g.display[y])
+	}
+
+	c.markAllDirty()
+
+	return c
+}
+
+func (g *Graphics) markDirty(r image.Rectangle) {
+	g.dirty = g.dirty.Union(r)
+}
+
+func (g *Graphics) markAllDirty() {
+	g.markDirty(image.Rect(0, 0, g.Width, g.Height))
+}
+
+// Dirty reports whether the display changed since the last EndFrame.
+func (g *Graphics) Dirty() bool {
+	return !g.dirty.Empty()
+}
+
+// DirtyRect returns the smallest rectangle, in display pixels, containing
+// every change since the last EndFrame.
+func (g *Graphics) DirtyRect() image.Rectangle {
+	return g.dirty
+}
+
+// Frame returns how many frames were ended, frontends use it to tell
+// frames apart when skipping unchanged ones.
+func (g *Graphics) Frame() uint64 {
+	return g.frame
+}
+
+// EndFrame is called by frontends after presenting the display, it advances
+// the frame counter and resets the dirty region.
+func (g *Graphics) EndFrame() {
+	g.frame++
+	g.dirty = image.Rectangle{}
+}
+
 func (g *Graphics) Clear() {
 	for i := 0; i < g.Height; i++ {
 		for j := 0; j < g.Width; j++ {
 			g.display[i][j] = 0x00
 		}
 	}
+
+	g.markAllDirty()
 }
 
 func (g *Graphics) GetPixel(y int, x int) byte {
@@ -40,7 +106,12 @@ func (g *Graphics) GetPixel(y int, x int) byte {
 }
 
 func (g *Graphics) SetPixel(y int, x int, b byte) {
+	if g.display[y][x] == b {
+		return
+	}
+
 	g.display[y][x] = b
+	g.markDirty(image.Rect(x, y, x+1, y+1))
 }
 
 func (g *Graphics) EnableHighResolutionMode() {
@@ -48,6 +119,7 @@ func (g *Graphics) EnableHighResolutionMode() {
 	g.Height = 0x40
 
 	g.display = newDisplay(g.Height, g.Width)
+	g.markAllDirty()
 }
 
 func (g *Graphics) DisableHighResolutionMode() {
@@ -55,10 +127,11 @@ func (g *Graphics) DisableHighResolutionMode() {
 	g.Height = 0x20
 
 	g.display = newDisplay(g.Height, g.Width)
+	g.markAllDirty()
 }
 
 func (g *Graphics) ScrollDown(shift uint8) {
-	s := int(shift)
+	s := min(int(shift), g.Height)
 
 	for i := g.Height - 1; i >= s; i-- {
 		g.display[i] = g.display[i-s]
@@ -67,10 +140,26 @@ func (g *Graphics) ScrollDown(shift uint8) {
 	for i := 0; i < s; i++ {
 		g.display[i] = make([]byte, g.Width)
 	}
+
+	g.markAllDirty()
+}
+
+func (g *Graphics) ScrollUp(shift uint8) {
+	s := min(int(shift), g.Height)
+
+	for i := 0; i < g.Height-s; i++ {
+		g.display[i] = g.display[i+s]
+	}
+
+	for i := g.Height - s; i < g.Height; i++ {
+		g.display[i] = make([]byte, g.Width)
+	}
+
+	g.markAllDirty()
 }
 
-func (g *Graphics) ScrollRight() {
-	s := 4
+func (g *Graphics) ScrollRight(shift uint8) {
+	s := int(shift)
 
 	for i := 0; i < g.Height; i++ {
 		for j := g.Width - 1; j >= 0; j-- {
@@ -81,10 +170,12 @@ func (g *Graphics) ScrollRight() {
 			}
 		}
 	}
+
+	g.markAllDirty()
 }
 
-func (g *Graphics) ScrollLeft() {
-	s := 4
+func (g *Graphics) ScrollLeft(shift uint8) {
+	s := int(shift)
 
 	for i := 0; i < g.Height; i++ {
 		for j := 0; j < g.Width; j++ {
@@ -95,4 +186,6 @@ func (g *Graphics) ScrollLeft() {
 			}
 		}
 	}
+
+	g.markAllDirty()
 }
@@ -1,6 +1,8 @@
 package graphics_test
 
 import (
+	"image"
+	"image/color"
 	"testing"
 
 	"github.com/gaoliveira21/chip8/core/graphics"
@@ -15,3 +17,86 @@ func TestSetAndGetPixel(t *testing.T) {
 		t.Errorf("graphics.Display[0][0] = 0x%X; expected 0x01", g.GetPixel(0, 0))
 	}
 }
+
+func TestDirtyRect(t *testing.T) {
+	g := graphics.NewGraphics()
+
+	if g.Dirty() {
+		t.Error("graphics.Dirty() = true; expected false")
+	}
+
+	g.SetPixel(2, 3, 0x1)
+	g.SetPixel(4, 1, 0x1)
+
+	expected := image.Rect(1, 2, 4, 5)
+
+	if g.DirtyRect() != expected {
+		t.Errorf("graphics.DirtyRect() = %v; expected %v", g.DirtyRect(), expected)
+	}
+
+	g.EndFrame()
+
+	if g.Dirty() {
+		t.Error("graphics.Dirty() = true; expected false")
+	}
+
+	if g.Frame() != 1 {
+		t.Errorf("graphics.Frame() = %d; expected 1", g.Frame())
+	}
+
+	g.SetPixel(2, 3, 0x1)
+
+	if g.Dirty() {
+		t.Error("graphics.Dirty() = true after writing the same value; expected false")
+	}
+}
+
+func TestDirtyAfterScroll(t *testing.T) {
+	g := graphics.NewGraphics()
+
+	g.ScrollLeft(4)
+
+	expected := image.Rect(0, 0, g.Width, g.Height)
+
+	if g.DirtyRect() != expected {
+		t.Errorf("graphics.DirtyRect() = %v; expected %v", g.DirtyRect(), expected)
+	}
+}
+
+func TestScrollUp(t *testing.T) {
+	g := graphics.NewGraphics()
+
+	g.SetPixel(5, 0, 0x1)
+	g.ScrollUp(3)
+
+	if g.GetPixel(2, 0) != 0x1 {
+		t.Errorf("graphics.Display[2][0] = 0x%X; expected 0x01", g.GetPixel(2, 0))
+	}
+
+	if g.GetPixel(5, 0) != 0x0 {
+		t.Errorf("graphics.Display[5][0] = 0x%X; expected 0x00", g.GetPixel(5, 0))
+	}
+
+	g.ScrollUp(0xFF)
+
+	if g.GetPixel(2, 0) != 0x0 {
+		t.Errorf("graphics.Display[2][0] = 0x%X; expected 0x00", g.GetPixel(2, 0))
+	}
+}
+
+func TestColorGraphicsBlend(t *testing.T) {
+	g := graphics.NewColorGraphics()
+
+	g.SetPixel(0, 0, color.RGBA{0x80, 0x00, 0x00, 0xFF}, 0x1, graphics.BLEND_NORMAL)
+	g.SetPixel(0, 0, color.RGBA{0x80, 0x10, 0x00, 0xFF}, 0x2, graphics.BLEND_ADD)
+
+	expected := color.RGBA{0xFF, 0x10, 0x00, 0xFF}
+
+	if g.GetPixel(0, 0) != expected {
+		t.Errorf("GetPixel(0, 0) = %v; expected %v", g.GetPixel(0, 0), expected)
+	}
+
+	if g.GetIndex(0, 0) != 0x2 {
+		t.Errorf("GetIndex(0, 0) = 0x%X; expected 0x02", g.GetIndex(0, 0))
+	}
+}
@@ -0,0 +1,81 @@
+package graphics
+
+import "image/color"
+
+// VP-590 color board palette
+var CHIP8X_COLORS = [8]color.RGBA{
+	{0x00, 0x00, 0x00, 0xFF}, // Black
+	{0xFF, 0x00, 0x00, 0xFF}, // Red
+	{0x00, 0x00, 0xFF, 0xFF}, // Blue
+	{0xFF, 0x00, 0xFF, 0xFF}, // Violet
+	{0x00, 0xFF, 0x00, 0xFF}, // Green
+	{0xFF, 0xFF, 0x00, 0xFF}, // Yellow
+	{0x00, 0xFF, 0xFF, 0xFF}, // Aqua
+	{0xFF, 0xFF, 0xFF, 0xFF}, // White
+}
+
+// Background colors stepped through by 02A0.
+var CHIP8X_BACKGROUNDS = [4]byte{2, 0, 4, 1}
+
+const (
+	ZONE_COLUMNS = 8
+	ZONE_WIDTH   = 8
+	ZONE_ROWS    = 32
+)
+
+// ColorZones is the CHIP-8X color model laid over the 64x32 display, the
+// foreground color is set per 8 pixels wide zone of a single row.
+type ColorZones struct {
+	background int
+	zones      [ZONE_ROWS][ZONE_COLUMNS]byte
+	version    uint64
+}
+
+func NewColorZones() *ColorZones {
+	z := &ColorZones{}
+
+	for y := range z.zones {
+		for x := range z.zones[y] {
+			z.zones[y][x] = 1
+		}
+	}
+
+	return z
+}
+
+func (z *ColorZones) Clone() *ColorZones {
+	c := *z
+	return &c
+}
+
+// Version changes every time a color changes, renderers compare it with the
+// last rendered version to find out whether the colors are up to date.
+func (z *ColorZones) Version() uint64 {
+	return z.version
+}
+
+func (z *ColorZones) Background() color.RGBA {
+	return CHIP8X_COLORS[CHIP8X_BACKGROUNDS[z.background]]
+}
+
+func (z *ColorZones) StepBackground() {
+	z.background = (z.background + 1) % len(CHIP8X_BACKGROUNDS)
+	z.version++
+}
+
+// Foreground returns the color of a lit pixel of the 64x32 display.
+func (z *ColorZones) Foreground(y int, x int) color.RGBA {
+	return CHIP8X_COLORS[z.zones[y%ZONE_ROWS][(x/ZONE_WIDTH)%ZONE_COLUMNS]]
+}
+
+// SetZones colors cols zones starting at col, on rows starting at row, the
+// coordinates wrap around the display.
+func (z *ColorZones) SetZones(row int, rows int, col int, cols int, c byte) {
+	for y := row; y < row+rows; y++ {
+		for x := col; x < col+cols; x++ {
+			z.zones[y%ZONE_ROWS][x%ZONE_COLUMNS] = c & 0x7
+		}
+	}
+
+	z.version++
+}
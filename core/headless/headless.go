@@ -0,0 +1,63 @@
+package headless
+
+import "github.com/gaoliveira21/chip8/core/cpu"
+
+// Runner runs a CPU without window, input devices or audio, one frame at a
+// time like the desktop frontend, for tests and tools.
+type Runner struct {
+	CPU   *cpu.CPU
+	Frame int // Frames run so far
+}
+
+func NewRunner(platform cpu.Platform, rom []byte) *Runner {
+	c := cpu.NewCpuForPlatform(platform)
+	c.LoadROM(rom)
+
+	return &Runner{CPU: &c}
+}
+
+// Step runs the instructions of one frame.
+func (r *Runner) Step() error {
+	for i := 0; i < cpu.SPEED; i++ {
+		if err := r.CPU.Run(); err != nil {
+			return err
+		}
+	}
+
+	r.Frame++
+
+	return nil
+}
+
+// Run steps n frames.
+func (r *Runner) Run(frames int) error {
+	for i := 0; i < frames; i++ {
+		if err := r.Step(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Runner) Press(key uint8) {
+	r.CPU.Keys[key] = 0x01
+}
+
+func (r *Runner) Release(key uint8) {
+	r.CPU.Keys[key] = 0x00
+}
+
+// Tap holds key for the given number of frames, then releases it and steps
+// one more frame so the ROM sees the release.
+func (r *Runner) Tap(key uint8, frames int) error {
+	r.Press(key)
+
+	if err := r.Run(frames); err != nil {
+		return err
+	}
+
+	r.Release(key)
+
+	return r.Step()
+}
@@ -0,0 +1,108 @@
+package headless_test
+
+import (
+	"testing"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/headless"
+)
+
+// waitKeyROM stores the next key in V0, then sets V1 to 1 and loops.
+var waitKeyROM = []byte{
+	0xF0, 0x0A, // LD V0, K
+	0x61, 0x01, // LD V1, 0x01
+	0x12, 0x04, // JP 0x204
+}
+
+func TestWaitKeyPressAndRelease(t *testing.T) {
+	r := headless.NewRunner(cpu.PLATFORM_CHIP8, waitKeyROM)
+	r.Run(2)
+
+	r.Press(0x7)
+	r.Run(5)
+
+	if v := r.CPU.V(0x1); v != 0x00 {
+		t.Errorf("V1 = 0x%X; expected 0x0 while the key is held", v)
+	}
+
+	r.Release(0x7)
+	r.Run(1)
+
+	if v := r.CPU.V(0x0); v != 0x07 {
+		t.Errorf("V0 = 0x%X; expected 0x7", v)
+	}
+
+	if v := r.CPU.V(0x1); v != 0x01 {
+		t.Errorf("V1 = 0x%X; expected 0x1", v)
+	}
+}
+
+func TestWaitKeyIgnoresKeyHeldBefore(t *testing.T) {
+	r := headless.NewRunner(cpu.PLATFORM_CHIP8, waitKeyROM)
+	r.Press(0x3)
+	r.Run(2)
+	r.Release(0x3)
+	r.Run(2)
+
+	if v := r.CPU.V(0x1); v != 0x00 {
+		t.Errorf("V1 = 0x%X; expected 0x0 for a key held before FX0A", v)
+	}
+
+	r.Tap(0xB, 3)
+
+	if v := r.CPU.V(0x0); v != 0x0B {
+		t.Errorf("V0 = 0x%X; expected 0xB", v)
+	}
+}
+
+func TestWaitKeySequence(t *testing.T) {
+	// Reads two keys into V0 and V1 then loops
+	rom := []byte{
+		0xF0, 0x0A, // LD V0, K
+		0xF1, 0x0A, // LD V1, K
+		0x12, 0x04, // JP 0x204
+	}
+
+	r := headless.NewRunner(cpu.PLATFORM_CHIP8, rom)
+	r.Run(1)
+
+	// A held key does not repeat into the second FX0A
+	r.Press(0x4)
+	r.Run(1)
+	r.Release(0x4)
+	r.Run(1)
+	r.Press(0x4)
+	r.Run(1)
+
+	if v := r.CPU.V(0x1); v != 0x00 {
+		t.Errorf("V1 = 0x%X; expected 0x0 before the second release", v)
+	}
+
+	r.Release(0x4)
+	r.Run(1)
+
+	if v := r.CPU.V(0x0); v != 0x04 {
+		t.Errorf("V0 = 0x%X; expected 0x4", v)
+	}
+
+	if v := r.CPU.V(0x1); v != 0x04 {
+		t.Errorf("V1 = 0x%X; expected 0x4", v)
+	}
+}
+
+func TestWaitKeyPressOnly(t *testing.T) {
+	r := headless.NewRunner(cpu.PLATFORM_CHIP8, waitKeyROM)
+	r.CPU.Quirks.KeyPressOnly = true
+	r.Run(1)
+
+	r.Press(0xE)
+	r.Run(1)
+
+	if v := r.CPU.V(0x0); v != 0x0E {
+		t.Errorf("V0 = 0x%X; expected 0xE", v)
+	}
+
+	if v := r.CPU.V(0x1); v != 0x01 {
+		t.Errorf("V1 = 0x%X; expected 0x1 without releasing the key", v)
+	}
+}
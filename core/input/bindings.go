@@ -0,0 +1,183 @@
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gaoliveira21/chip8/core/config"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// NewKeyboardFromConfig creates the keyboard described by the controls
+// section of the configuration, the bindings of the layout preset are used
+// when no key is set. Key names follow the binding mode, ebiten.Key names in
+// scancode mode and the characters printed on the keys in character mode.
+func NewKeyboardFromConfig(c config.Controls) (*Keyboard, error) {
+	names := map[string]uint8{}
+
+	if len(c.Keyboard) == 0 {
+		layout, err := Layout(c.Layout)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for key, value := range layout {
+			if c.Mode == config.KEYBOARD_CHARACTER {
+				names[qwertyCharacter(key)] = value
+			} else {
+				names[key.String()] = value
+			}
+		}
+	}
+
+	for name, k := range c.Keyboard {
+		value, err := parseKey(k)
+
+		if err != nil {
+			return nil, err
+		}
+
+		names[name] = value
+	}
+
+	switch c.Mode {
+	case config.KEYBOARD_CHARACTER:
+		characters := map[string]uint8{}
+
+		for name, value := range names {
+			// Characters are lower case, keys without one keep their name
+			if len([]rune(name)) == 1 {
+				name = strings.ToLower(name)
+			}
+
+			characters[name] = value
+		}
+
+		return NewCharacterKeyboard(characters), nil
+	case config.KEYBOARD_SCANCODE, "":
+		bindings := map[ebiten.Key]uint8{}
+
+		for name, value := range names {
+			var key ebiten.Key
+
+			if err := key.UnmarshalText([]byte(name)); err != nil {
+				return nil, err
+			}
+
+			bindings[key] = value
+		}
+
+		return NewKeyboard(bindings), nil
+	}
+
+	return nil, fmt.Errorf("input: unknown keyboard mode %q", c.Mode)
+}
+
+// Keypad2Bindings returns the bindings of the second CHIP-8X keypad set in
+// the keyboard2 section of the configuration, or the first of
+// Keypad2Presets not using the keys of player1. Keys of player1 cannot be
+// bound to the second keypad.
+func Keypad2Bindings(c config.Controls, player1 map[ebiten.Key]uint8) (map[ebiten.Key]uint8, error) {
+	if len(c.Keyboard2) == 0 {
+		for _, preset := range Keypad2Presets {
+			if !overlaps(preset, player1) {
+				return preset, nil
+			}
+		}
+
+		return nil, fmt.Errorf("input: the second keypad presets use keys of the first keypad, set keyboard2")
+	}
+
+	bindings := map[ebiten.Key]uint8{}
+
+	for name, k := range c.Keyboard2 {
+		var key ebiten.Key
+
+		if err := key.UnmarshalText([]byte(name)); err != nil {
+			return nil, err
+		}
+
+		value, err := parseKey(k)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := player1[key]; ok {
+			return nil, fmt.Errorf("input: %s is bound to both keypads", key)
+		}
+
+		bindings[key] = value
+	}
+
+	return bindings, nil
+}
+
+func overlaps(a, b map[ebiten.Key]uint8) bool {
+	for key := range a {
+		if _, ok := b[key]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GamepadBindings parses the gamepad section of the configuration, buttons
+// are named as in GamepadButtons. It returns a copy of GamepadPad when no
+// binding is set.
+func GamepadBindings(c config.Controls) (map[ebiten.StandardGamepadButton]uint8, error) {
+	bindings := map[ebiten.StandardGamepadButton]uint8{}
+
+	if len(c.Gamepad) == 0 {
+		for button, value := range GamepadPad {
+			bindings[button] = value
+		}
+
+		return bindings, nil
+	}
+
+	for name, k := range c.Gamepad {
+		button, ok := GamepadButtons[name]
+
+		if !ok {
+			return nil, fmt.Errorf("input: unknown gamepad button %q", name)
+		}
+
+		value, err := parseKey(k)
+
+		if err != nil {
+			return nil, err
+		}
+
+		bindings[button] = value
+	}
+
+	return bindings, nil
+}
+
+// SaveBindings writes the bindings to the controls section of c.
+func SaveBindings(c *config.Controls, keyboard *Keyboard, gamepad map[ebiten.StandardGamepadButton]uint8) {
+	c.Keyboard = map[string]string{}
+	c.Gamepad = map[string]string{}
+
+	for name, value := range keyboard.Names() {
+		c.Keyboard[name] = fmt.Sprintf("%X", value)
+	}
+
+	for button, value := range gamepad {
+		c.Gamepad[GamepadButtonName(button)] = fmt.Sprintf("%X", value)
+	}
+}
+
+func parseKey(k string) (uint8, error) {
+	value, err := strconv.ParseUint(k, 16, 8)
+
+	if err != nil || value > 0x0F {
+		return 0, fmt.Errorf("input: invalid CHIP-8 key %q", k)
+	}
+
+	return uint8(value), nil
+}
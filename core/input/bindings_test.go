@@ -0,0 +1,175 @@
+package input_test
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/gaoliveira21/chip8/core/config"
+	"github.com/gaoliveira21/chip8/core/input"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestNewKeyboardFromConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		controls config.Controls
+		expected map[ebiten.Key]uint8 // nil for an error
+	}{
+		{"qwerty preset", config.Controls{Layout: config.LAYOUT_QWERTY, Mode: config.KEYBOARD_SCANCODE}, input.Keypad},
+		{"numpad preset", config.Controls{Layout: config.LAYOUT_NUMPAD, Mode: config.KEYBOARD_SCANCODE}, input.Numpad},
+		{"hex preset", config.Controls{Layout: config.LAYOUT_HEX}, input.Hex},
+		{"keys replace the preset", config.Controls{Layout: config.LAYOUT_HEX, Keyboard: map[string]string{"W": "5", "ArrowUp": "c"}}, map[ebiten.Key]uint8{ebiten.KeyW: 0x5, ebiten.KeyArrowUp: 0xC}},
+		{"key above F", config.Controls{Keyboard: map[string]string{"W": "10"}}, nil},
+		{"key not hexadecimal", config.Controls{Keyboard: map[string]string{"W": "G"}}, nil},
+		{"unknown key name", config.Controls{Keyboard: map[string]string{"Nope": "1"}}, nil},
+		{"unknown layout", config.Controls{Layout: "dvorak"}, nil},
+		{"unknown mode", config.Controls{Layout: config.LAYOUT_QWERTY, Mode: "braille"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := input.NewKeyboardFromConfig(tt.controls)
+
+			if tt.expected == nil {
+				if err == nil {
+					t.Errorf("bindings = %v; expected an error", k.Bindings)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if k.Mode != config.KEYBOARD_SCANCODE {
+				t.Errorf("Mode = %s; expected %s", k.Mode, config.KEYBOARD_SCANCODE)
+			}
+
+			if !maps.Equal(k.Bindings, tt.expected) {
+				t.Errorf("bindings = %v; expected %v", k.Bindings, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGamepadBindings(t *testing.T) {
+	tests := []struct {
+		name     string
+		gamepad  map[string]string
+		expected map[ebiten.StandardGamepadButton]uint8 // nil for an error
+	}{
+		{"defaults", nil, input.GamepadPad},
+		{"buttons", map[string]string{"A": "6", "Up": "2"}, map[ebiten.StandardGamepadButton]uint8{ebiten.StandardGamepadButtonRightBottom: 0x6, ebiten.StandardGamepadButtonLeftTop: 0x2}},
+		{"unknown button", map[string]string{"Z": "1"}, nil},
+		{"key above F", map[string]string{"A": "1F"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bindings, err := input.GamepadBindings(config.Controls{Gamepad: tt.gamepad})
+
+			if tt.expected == nil {
+				if err == nil {
+					t.Errorf("bindings = %v; expected an error", bindings)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !maps.Equal(bindings, tt.expected) {
+				t.Errorf("bindings = %v; expected %v", bindings, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGamepadBindingsCopiesDefaults(t *testing.T) {
+	bindings, err := input.GamepadBindings(config.Controls{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bindings[ebiten.StandardGamepadButtonLeftTop] = 0x0
+
+	if input.GamepadPad[ebiten.StandardGamepadButtonLeftTop] != 0x5 {
+		t.Error("GamepadPad changed with the bindings returned")
+	}
+}
+
+func TestSaveBindings(t *testing.T) {
+	keyboard := input.NewKeyboard(map[ebiten.Key]uint8{ebiten.KeyW: 0x5, ebiten.KeySpace: 0xC})
+	gamepad := map[ebiten.StandardGamepadButton]uint8{ebiten.StandardGamepadButtonRightBottom: 0xA}
+	c := config.Controls{Layout: config.LAYOUT_QWERTY, Mode: config.KEYBOARD_SCANCODE}
+
+	input.SaveBindings(&c, keyboard, gamepad)
+
+	if c.Keyboard["Space"] != "C" || c.Gamepad["A"] != "A" {
+		t.Errorf("controls = %v, %v; expected Space C and A A", c.Keyboard, c.Gamepad)
+	}
+
+	k, err := input.NewKeyboardFromConfig(c)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !maps.Equal(k.Bindings, keyboard.Bindings) {
+		t.Errorf("keyboard = %v; expected %v", k.Bindings, keyboard.Bindings)
+	}
+
+	g, err := input.GamepadBindings(c)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !maps.Equal(g, gamepad) {
+		t.Errorf("gamepad = %v; expected %v", g, gamepad)
+	}
+}
+
+func TestKeypad2Bindings(t *testing.T) {
+	tests := []struct {
+		name      string
+		keyboard2 map[string]string
+		player1   map[ebiten.Key]uint8
+		expected  map[ebiten.Key]uint8 // nil for an error
+	}{
+		{"numpad next to qwerty", nil, input.Keypad, input.Numpad},
+		{"numpad next to hex", nil, input.Hex, input.Numpad},
+		{"qwerty next to numpad", nil, input.Numpad, input.Keypad},
+		{"no free preset", nil, map[ebiten.Key]uint8{ebiten.KeyNumpad0: 0x0, ebiten.KeyQ: 0x4, ebiten.KeyA: 0xA}, nil},
+		{"keys", map[string]string{"I": "2", "K": "8"}, input.Keypad, map[ebiten.Key]uint8{ebiten.KeyI: 0x2, ebiten.KeyK: 0x8}},
+		{"key of the first keypad", map[string]string{"I": "2", "W": "5"}, input.Keypad, nil},
+		{"key above F", map[string]string{"I": "10"}, input.Keypad, nil},
+		{"unknown key name", map[string]string{"Nope": "1"}, input.Keypad, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bindings, err := input.Keypad2Bindings(config.Controls{Keyboard2: tt.keyboard2}, tt.player1)
+
+			if tt.expected == nil {
+				if err == nil {
+					t.Errorf("bindings = %v; expected an error", bindings)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !maps.Equal(bindings, tt.expected) {
+				t.Errorf("bindings = %v; expected %v", bindings, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package input
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// GamepadButtons names the buttons of the standard gamepad layout, using the
+// Xbox labels for the face buttons.
+var GamepadButtons = map[string]ebiten.StandardGamepadButton{
+	"A":     ebiten.StandardGamepadButtonRightBottom,
+	"B":     ebiten.StandardGamepadButtonRightRight,
+	"X":     ebiten.StandardGamepadButtonRightLeft,
+	"Y":     ebiten.StandardGamepadButtonRightTop,
+	"LB":    ebiten.StandardGamepadButtonFrontTopLeft,
+	"RB":    ebiten.StandardGamepadButtonFrontTopRight,
+	"LT":    ebiten.StandardGamepadButtonFrontBottomLeft,
+	"RT":    ebiten.StandardGamepadButtonFrontBottomRight,
+	"Back":  ebiten.StandardGamepadButtonCenterLeft,
+	"Start": ebiten.StandardGamepadButtonCenterRight,
+	"Home":  ebiten.StandardGamepadButtonCenterCenter,
+	"LS":    ebiten.StandardGamepadButtonLeftStick,
+	"RS":    ebiten.StandardGamepadButtonRightStick,
+	"Up":    ebiten.StandardGamepadButtonLeftTop,
+	"Down":  ebiten.StandardGamepadButtonLeftBottom,
+	"Left":  ebiten.StandardGamepadButtonLeftLeft,
+	"Right": ebiten.StandardGamepadButtonLeftRight,
+}
+
+// GamepadPad follows the WASD layout used by most CHIP-8 games, the D-pad
+// is 5 7 8 9 and the face buttons are 6 4 1 C.
+var GamepadPad = map[ebiten.StandardGamepadButton]uint8{
+	ebiten.StandardGamepadButtonLeftTop:     0x05,
+	ebiten.StandardGamepadButtonLeftLeft:    0x07,
+	ebiten.StandardGamepadButtonLeftBottom:  0x08,
+	ebiten.StandardGamepadButtonLeftRight:   0x09,
+	ebiten.StandardGamepadButtonRightBottom: 0x06,
+	ebiten.StandardGamepadButtonRightRight:  0x04,
+	ebiten.StandardGamepadButtonRightLeft:   0x01,
+	ebiten.StandardGamepadButtonRightTop:    0x0C,
+	ebiten.StandardGamepadButtonCenterRight: 0x0F,
+}
+
+// Gamepad reads every connected gamepad with a standard layout mapping.
+type Gamepad struct {
+	Bindings map[ebiten.StandardGamepadButton]uint8
+	ids      []ebiten.GamepadID
+}
+
+func NewGamepad(bindings map[ebiten.StandardGamepadButton]uint8) *Gamepad {
+	return &Gamepad{Bindings: bindings}
+}
+
+func (g *Gamepad) Read(keys []uint8) {
+	g.ids = ebiten.AppendGamepadIDs(g.ids[:0])
+
+	for _, id := range g.ids {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+
+		for button, value := range g.Bindings {
+			if ebiten.IsStandardGamepadButtonPressed(id, button) {
+				keys[value] = 0x01
+			}
+		}
+	}
+}
+
+// GamepadButtonName returns the name of button used in the configuration.
+func GamepadButtonName(button ebiten.StandardGamepadButton) string {
+	for name, b := range GamepadButtons {
+		if b == button {
+			return name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,165 @@
+package input
+
+import (
+	"strings"
+
+	"github.com/gaoliveira21/chip8/core/config"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Keyboard reads the keyboard. In scancode mode the bindings are key
+// positions, in character mode they are the characters printed on the keys
+// and follow the keyboard layout of the system, a binding to Q is the A key
+// of an AZERTY keyboard.
+type Keyboard struct {
+	Bindings map[ebiten.Key]uint8
+	Mode     string
+
+	characters map[string]uint8
+	resolved   bool
+}
+
+func NewKeyboard(bindings map[ebiten.Key]uint8) *Keyboard {
+	return &Keyboard{
+		Bindings: bindings,
+		Mode:     config.KEYBOARD_SCANCODE,
+	}
+}
+
+// NewCharacterKeyboard binds characters to CHIP-8 keys, they are resolved
+// to key positions once the system keyboard layout is known and until then
+// the QWERTY positions are used.
+func NewCharacterKeyboard(characters map[string]uint8) *Keyboard {
+	k := &Keyboard{
+		Bindings:   map[ebiten.Key]uint8{},
+		Mode:       config.KEYBOARD_CHARACTER,
+		characters: characters,
+	}
+
+	for char, value := range characters {
+		if key, ok := qwertyKey(char); ok {
+			k.Bindings[key] = value
+		}
+	}
+
+	return k
+}
+
+func (k *Keyboard) Read(keys []uint8) {
+	if k.Mode == config.KEYBOARD_CHARACTER && !k.resolved {
+		k.resolve()
+	}
+
+	for key, value := range k.Bindings {
+		if ebiten.IsKeyPressed(key) {
+			keys[value] = 0x01
+		}
+	}
+}
+
+// SetBindings replaces the bindings with key positions, in character mode
+// they are stored as the characters of the keys.
+func (k *Keyboard) SetBindings(bindings map[ebiten.Key]uint8) {
+	k.Bindings = bindings
+
+	if k.Mode != config.KEYBOARD_CHARACTER {
+		return
+	}
+
+	k.characters = map[string]uint8{}
+
+	for key, value := range bindings {
+		k.characters[Character(key)] = value
+	}
+}
+
+// Names returns the bindings as written in the configuration file.
+func (k *Keyboard) Names() map[string]uint8 {
+	if k.Mode == config.KEYBOARD_CHARACTER {
+		return k.characters
+	}
+
+	names := map[string]uint8{}
+
+	for key, value := range k.Bindings {
+		names[key.String()] = value
+	}
+
+	return names
+}
+
+// resolve maps the characters to the keys printing them, ebiten.KeyName
+// only knows the layout once the game loop runs and not on every platform.
+func (k *Keyboard) resolve() {
+	layout := map[string]ebiten.Key{}
+
+	for key := ebiten.Key(0); key <= ebiten.KeyMax; key++ {
+		// The numeric keypad prints digits too but does not depend on the layout
+		if strings.HasPrefix(key.String(), "Numpad") {
+			continue
+		}
+
+		if name := ebiten.KeyName(key); name != "" {
+			layout[strings.ToLower(name)] = key
+		}
+	}
+
+	if len(layout) == 0 {
+		return
+	}
+
+	bindings := map[ebiten.Key]uint8{}
+
+	for char, value := range k.characters {
+		if key, ok := layout[char]; ok {
+			bindings[key] = value
+		} else if key, ok := qwertyKey(char); ok {
+			bindings[key] = value
+		}
+	}
+
+	k.Bindings = bindings
+	k.resolved = true
+}
+
+// Character returns the character printed on key, keys without one such as
+// Space or ArrowUp are named as ebiten.Key. So are the numeric keypad keys,
+// their digits would be the keys of the top row.
+func Character(key ebiten.Key) string {
+	if strings.HasPrefix(key.String(), "Numpad") {
+		return key.String()
+	}
+
+	if name := ebiten.KeyName(key); name != "" {
+		return strings.ToLower(name)
+	}
+
+	return qwertyCharacter(key)
+}
+
+// qwertyKey returns the key printing char on a QWERTY keyboard.
+func qwertyKey(char string) (ebiten.Key, bool) {
+	var key ebiten.Key
+
+	if err := key.UnmarshalText([]byte(char)); err != nil {
+		return 0, false
+	}
+
+	return key, true
+}
+
+// qwertyCharacter returns the character printed on key in a QWERTY layout,
+// the presets are written for it.
+func qwertyCharacter(key ebiten.Key) string {
+	name := key.String()
+
+	if strings.HasPrefix(name, "Digit") {
+		return strings.TrimPrefix(name, "Digit")
+	}
+
+	if len(name) == 1 {
+		return strings.ToLower(name)
+	}
+
+	return name
+}
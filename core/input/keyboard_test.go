@@ -0,0 +1,82 @@
+package input_test
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/gaoliveira21/chip8/core/config"
+	"github.com/gaoliveira21/chip8/core/input"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestCharacterPresets(t *testing.T) {
+	tests := []struct {
+		layout string
+		preset map[ebiten.Key]uint8
+		names  map[string]uint8 // Some of the expected names
+	}{
+		{config.LAYOUT_QWERTY, input.Keypad, map[string]uint8{"1": 0x1, "q": 0x4, "v": 0xF}},
+		{config.LAYOUT_NUMPAD, input.Numpad, map[string]uint8{"Numpad7": 0x1, "NumpadEnter": 0xB}},
+		{config.LAYOUT_HEX, input.Hex, map[string]uint8{"0": 0x0, "a": 0xA, "f": 0xF}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			k, err := input.NewKeyboardFromConfig(config.Controls{Layout: tt.layout, Mode: config.KEYBOARD_CHARACTER})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if k.Mode != config.KEYBOARD_CHARACTER {
+				t.Errorf("Mode = %s; expected %s", k.Mode, config.KEYBOARD_CHARACTER)
+			}
+
+			// Until the system layout is known the characters are QWERTY keys
+			if !maps.Equal(k.Bindings, tt.preset) {
+				t.Errorf("bindings = %v; expected %v", k.Bindings, tt.preset)
+			}
+
+			names := k.Names()
+
+			if len(names) != len(tt.preset) {
+				t.Errorf("len(names) = %d; expected %d", len(names), len(tt.preset))
+			}
+
+			for name, value := range tt.names {
+				if v, ok := names[name]; !ok || v != value {
+					t.Errorf("names[%q] = 0x%X, %t; expected 0x%X", name, v, ok, value)
+				}
+			}
+		})
+	}
+}
+
+func TestCharacterKeyboard(t *testing.T) {
+	k := input.NewCharacterKeyboard(map[string]uint8{"q": 0x4, "Space": 0xC})
+
+	if expected := map[ebiten.Key]uint8{ebiten.KeyQ: 0x4, ebiten.KeySpace: 0xC}; !maps.Equal(k.Bindings, expected) {
+		t.Errorf("bindings = %v; expected %v", k.Bindings, expected)
+	}
+
+	if expected := map[string]uint8{"q": 0x4, "Space": 0xC}; !maps.Equal(k.Names(), expected) {
+		t.Errorf("names = %v; expected %v", k.Names(), expected)
+	}
+}
+
+func TestSetBindingsKeepsNumpadNames(t *testing.T) {
+	k := input.NewCharacterKeyboard(map[string]uint8{})
+	k.SetBindings(map[ebiten.Key]uint8{ebiten.KeyNumpad7: 0x1, ebiten.KeyNumpadAdd: 0xE})
+
+	if expected := map[string]uint8{"Numpad7": 0x1, "NumpadAdd": 0xE}; !maps.Equal(k.Names(), expected) {
+		t.Errorf("names = %v; expected %v", k.Names(), expected)
+	}
+}
+
+func TestScancodeNames(t *testing.T) {
+	k := input.NewKeyboard(map[ebiten.Key]uint8{ebiten.KeyQ: 0x4, ebiten.Key1: 0x1, ebiten.KeyNumpad7: 0x7})
+
+	if expected := map[string]uint8{"Q": 0x4, "Digit1": 0x1, "Numpad7": 0x7}; !maps.Equal(k.Names(), expected) {
+		t.Errorf("names = %v; expected %v", k.Names(), expected)
+	}
+}
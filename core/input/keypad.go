@@ -1,7 +1,14 @@
 package input
 
-import "github.com/hajimehoshi/ebiten/v2"
+import (
+	"fmt"
 
+	"github.com/gaoliveira21/chip8/core/config"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Keypad maps the left side of a QWERTY keyboard to the COSMAC VIP keypad,
+// key by key position.
 var Keypad = map[ebiten.Key]uint8{
 	ebiten.Key1: 0x01,
 	ebiten.Key2: 0x02,
@@ -20,3 +27,65 @@ var Keypad = map[ebiten.Key]uint8{
 	ebiten.KeyC: 0x0B,
 	ebiten.KeyV: 0x0F,
 }
+
+// Numpad lays the COSMAC VIP keypad out on the numeric keypad.
+var Numpad = map[ebiten.Key]uint8{
+	ebiten.KeyNumpad7:        0x01,
+	ebiten.KeyNumpad8:        0x02,
+	ebiten.KeyNumpad9:        0x03,
+	ebiten.KeyNumpadMultiply: 0x0C,
+	ebiten.KeyNumpad4:        0x04,
+	ebiten.KeyNumpad5:        0x05,
+	ebiten.KeyNumpad6:        0x06,
+	ebiten.KeyNumpadSubtract: 0x0D,
+	ebiten.KeyNumpad1:        0x07,
+	ebiten.KeyNumpad2:        0x08,
+	ebiten.KeyNumpad3:        0x09,
+	ebiten.KeyNumpadAdd:      0x0E,
+	ebiten.KeyNumpad0:        0x0A,
+	ebiten.KeyNumpadDecimal:  0x00,
+	ebiten.KeyNumpadEnter:    0x0B,
+	ebiten.KeyNumpadDivide:   0x0F,
+}
+
+// Hex binds every CHIP-8 key to the key with the same label, 0 to 9 and A
+// to F.
+var Hex = map[ebiten.Key]uint8{
+	ebiten.Key0: 0x00,
+	ebiten.Key1: 0x01,
+	ebiten.Key2: 0x02,
+	ebiten.Key3: 0x03,
+	ebiten.Key4: 0x04,
+	ebiten.Key5: 0x05,
+	ebiten.Key6: 0x06,
+	ebiten.Key7: 0x07,
+	ebiten.Key8: 0x08,
+	ebiten.Key9: 0x09,
+	ebiten.KeyA: 0x0A,
+	ebiten.KeyB: 0x0B,
+	ebiten.KeyC: 0x0C,
+	ebiten.KeyD: 0x0D,
+	ebiten.KeyE: 0x0E,
+	ebiten.KeyF: 0x0F,
+}
+
+var Layouts = map[string]map[ebiten.Key]uint8{
+	config.LAYOUT_QWERTY: Keypad,
+	config.LAYOUT_NUMPAD: Numpad,
+	config.LAYOUT_HEX:    Hex,
+}
+
+// Keypad2Presets are the default bindings of the second CHIP-8X keypad, the
+// first one without a key of the first keypad is used.
+var Keypad2Presets = []map[ebiten.Key]uint8{Numpad, Keypad, Hex}
+
+// Layout returns the preset bindings called name.
+func Layout(name string) (map[ebiten.Key]uint8, error) {
+	layout, ok := Layouts[name]
+
+	if !ok {
+		return nil, fmt.Errorf("input: unknown keyboard layout %q", name)
+	}
+
+	return layout, nil
+}
@@ -0,0 +1,21 @@
+package input
+
+// Source is a device the CHIP-8 keypad can be read from.
+type Source interface {
+	// Read sets keys[k] to 0x01 for every CHIP-8 key k held on the device,
+	// keys that are not held are left untouched.
+	Read(keys []uint8)
+}
+
+// Sources merges several devices, a key is held when any of them holds it.
+type Sources []Source
+
+func (s Sources) Read(keys []uint8) {
+	for i := range keys {
+		keys[i] = 0x00
+	}
+
+	for _, source := range s {
+		source.Read(keys)
+	}
+}
@@ -0,0 +1,51 @@
+package memory
+
+// HEAT_FRAMES is how long an access stays visible, in frames.
+const HEAT_FRAMES = 60
+
+// Heat tracks how recently each address was read, written and executed,
+// for memory viewers. Accesses beyond its size are ignored.
+type Heat struct {
+	heat [3][]uint8 // Frames left before the access fades, by access kind
+}
+
+func NewHeat(size int) *Heat {
+	h := &Heat{}
+
+	for kind := range h.heat {
+		h.heat[kind] = make([]uint8, size)
+	}
+
+	return h
+}
+
+func (h *Heat) Record(a Access) {
+	if int(a.Addr) < len(h.heat[a.Kind]) {
+		h.heat[a.Kind][a.Addr] = HEAT_FRAMES
+	}
+}
+
+// Cool advances one frame, older accesses fade out.
+func (h *Heat) Cool() {
+	for _, heat := range h.heat {
+		for i, v := range heat {
+			if v > 0 {
+				heat[i] = v - 1
+			}
+		}
+	}
+}
+
+// Get returns how recent the last access of kind to addr is, from
+// HEAT_FRAMES (this frame) down to 0 (none recently).
+func (h *Heat) Get(kind int, addr int) uint8 {
+	if addr >= len(h.heat[kind]) {
+		return 0
+	}
+
+	return h.heat[kind][addr]
+}
+
+func (h *Heat) Size() int {
+	return len(h.heat[ACCESS_READ])
+}
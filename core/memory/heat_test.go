@@ -0,0 +1,59 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/gaoliveira21/chip8/core/memory"
+)
+
+func TestRecorder(t *testing.T) {
+	m := memory.NewMMU(memory.RAM_SIZE)
+	accesses := []memory.Access{}
+	m.Recorder = func(a memory.Access) { accesses = append(accesses, a) }
+	m.PC = 0x0204
+
+	m.Write(0x300, 0x12)
+	m.Load(0x300)
+	m.Execute(0x204)
+
+	expected := []memory.Access{
+		{memory.ACCESS_WRITE, 0x300, 0x204},
+		{memory.ACCESS_READ, 0x300, 0x204},
+		{memory.ACCESS_EXECUTE, 0x204, 0x204},
+		{memory.ACCESS_EXECUTE, 0x205, 0x204},
+	}
+
+	if len(accesses) != len(expected) {
+		t.Fatalf("len(accesses) = %d; expected %d", len(accesses), len(expected))
+	}
+
+	for i, a := range accesses {
+		if a != expected[i] {
+			t.Errorf("accesses[%d] = %+v; expected %+v", i, a, expected[i])
+		}
+	}
+
+	m.Peek(0x300)
+
+	if len(accesses) != len(expected) {
+		t.Errorf("Peek recorded an access")
+	}
+}
+
+func TestHeat(t *testing.T) {
+	h := memory.NewHeat(memory.RAM_SIZE)
+	h.Record(memory.Access{Kind: memory.ACCESS_WRITE, Addr: 0x300})
+	h.Record(memory.Access{Kind: memory.ACCESS_READ, Addr: 0x10000})
+
+	if v := h.Get(memory.ACCESS_WRITE, 0x300); v != memory.HEAT_FRAMES {
+		t.Errorf("h.Get(ACCESS_WRITE, 0x300) = %d; expected %d", v, memory.HEAT_FRAMES)
+	}
+
+	for i := 0; i < memory.HEAT_FRAMES; i++ {
+		h.Cool()
+	}
+
+	if v := h.Get(memory.ACCESS_WRITE, 0x300); v != 0 {
+		t.Errorf("h.Get(ACCESS_WRITE, 0x300) = %d; expected 0 after cooling", v)
+	}
+}
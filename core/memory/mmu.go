@@ -1,20 +1,126 @@
 package memory
 
-const RAM_SIZE = 4096 // 4 KB
+const (
+	RAM_SIZE      = 4096      // 4 KB
+	MEGA_RAM_SIZE = 0x1000000 // 16 MB, addressable by the MEGA-CHIP 24 bit I register
+)
+
+const (
+	ACCESS_READ = iota
+	ACCESS_WRITE
+	ACCESS_EXECUTE
+)
+
+// Access is a memory access reported to the Recorder of the MMU.
+type Access struct {
+	Kind int
+	Addr uint32
+	PC   uint16 // Address of the instruction making the access
+}
 
 type MMU struct {
-	memory [RAM_SIZE]uint8
+	memory []uint8
 	Stack  Stack
+
+	// Recorder receives every access when set, PC is stamped on them and is
+	// updated by the CPU before each instruction.
+	Recorder func(a Access)
+	PC       uint16
+
+	// Watch is called on every write when set, with the previous and the
+	// new value of the byte.
+	Watch func(addr uint32, old byte, value byte)
+}
+
+func NewMMU(size int) MMU {
+	return MMU{
+		memory: make([]uint8, size),
+	}
+}
+
+// Clone returns a copy of the memory and the stack.
+func (m *MMU) Clone() MMU {
+	return MMU{
+		memory:   append([]uint8(nil), m.ram()...),
+		Stack:    m.Stack,
+		Recorder: m.Recorder,
+		PC:       m.PC,
+		Watch:    m.Watch,
+	}
+}
+
+// ram allocates the default 4 KB on first use so a zero MMU is ready to use.
+func (m *MMU) ram() []uint8 {
+	if m.memory == nil {
+		m.memory = make([]uint8, RAM_SIZE)
+	}
+
+	return m.memory
+}
+
+func (m *MMU) record(kind int, addr uint32) {
+	if m.Recorder != nil {
+		m.Recorder(Access{Kind: kind, Addr: addr, PC: m.PC})
+	}
+}
+
+func (m *MMU) Size() int {
+	return len(m.ram())
+}
+
+// wrap maps addresses past the end of the memory back to its start, like
+// the 12 bit addresses of a 4 KB CHIP-8.
+func (m *MMU) wrap(addr uint32) uint32 {
+	return addr % uint32(len(m.ram()))
 }
 
 func (m *MMU) Fetch(addr uint16) uint16 {
-	hb := uint16(m.memory[addr])
+	ram := m.ram()
+	hi, lo := m.wrap(uint32(addr)), m.wrap(uint32(addr)+1)
+
+	m.record(ACCESS_READ, hi)
+	m.record(ACCESS_READ, lo)
+
+	return uint16(ram[hi])<<8 | uint16(ram[lo])
+}
+
+// Execute fetches the instruction at addr.
+func (m *MMU) Execute(addr uint16) uint16 {
+	ram := m.ram()
+	hi, lo := m.wrap(uint32(addr)), m.wrap(uint32(addr)+1)
 
-	lb := uint16(m.memory[addr+1])
+	m.record(ACCESS_EXECUTE, hi)
+	m.record(ACCESS_EXECUTE, lo)
 
-	return (hb << 8) | lb
+	return uint16(ram[hi])<<8 | uint16(ram[lo])
 }
 
 func (m *MMU) Write(addr uint16, data byte) {
-	m.memory[addr] = data
+	m.Store(uint32(addr), data)
+}
+
+// Load reads a byte using the extended addresses of MEGA-CHIP.
+func (m *MMU) Load(addr uint32) byte {
+	addr = m.wrap(addr)
+	m.record(ACCESS_READ, addr)
+
+	return m.ram()[addr]
+}
+
+// Store writes a byte using the extended addresses of MEGA-CHIP.
+func (m *MMU) Store(addr uint32, data byte) {
+	ram := m.ram()
+	addr = m.wrap(addr)
+
+	if m.Watch != nil {
+		m.Watch(addr, ram[addr], data)
+	}
+
+	ram[addr] = data
+	m.record(ACCESS_WRITE, addr)
+}
+
+// Peek reads a byte without recording the access, for debuggers.
+func (m *MMU) Peek(addr uint32) byte {
+	return m.ram()[m.wrap(addr)]
 }
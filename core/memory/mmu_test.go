@@ -26,3 +26,61 @@ func TestMMU(t *testing.T) {
 		t.Errorf("Fetch(0x00) = %d; expected 0xAABB", word2)
 	}
 }
+
+func TestMMUWrap(t *testing.T) {
+	mmu := new(memory.MMU)
+
+	mmu.Write(0xFFF, 0xAA)
+	mmu.Write(0x1000, 0xBB)
+
+	if word := mmu.Fetch(0xFFF); word != 0xAABB {
+		t.Errorf("Fetch(0xFFF) = 0x%X; expected 0xAABB", word)
+	}
+
+	if b := mmu.Load(0x000); b != 0xBB {
+		t.Errorf("Load(0x000) = 0x%X; expected 0xBB written at 0x1000", b)
+	}
+}
+
+func TestMMUExtendedAddresses(t *testing.T) {
+	mmu := memory.NewMMU(memory.MEGA_RAM_SIZE)
+
+	mmu.Store(0xFFFFFF, 0xCC)
+	mmu.Store(0x012345, 0xDD)
+
+	if mmu.Load(0xFFFFFF) != 0xCC {
+		t.Errorf("Load(0xFFFFFF) = 0x%X; expected 0xCC", mmu.Load(0xFFFFFF))
+	}
+
+	if mmu.Load(0x012345) != 0xDD {
+		t.Errorf("Load(0x012345) = 0x%X; expected 0xDD", mmu.Load(0x012345))
+	}
+
+	if mmu.Size() != memory.MEGA_RAM_SIZE {
+		t.Errorf("Size() = %d; expected %d", mmu.Size(), memory.MEGA_RAM_SIZE)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	mmu := new(memory.MMU)
+	mmu.Write(0x300, 0x11)
+
+	var writes []memory.Access
+	var old, value byte
+
+	mmu.Watch = func(addr uint32, o byte, v byte) {
+		writes = append(writes, memory.Access{Kind: memory.ACCESS_WRITE, Addr: addr})
+		old, value = o, v
+	}
+
+	mmu.Load(0x300)
+	mmu.Write(0x300, 0x22)
+
+	if len(writes) != 1 || writes[0].Addr != 0x300 {
+		t.Fatalf("writes = %v; expected a write at 0x300", writes)
+	}
+
+	if old != 0x11 || value != 0x22 {
+		t.Errorf("write = 0x%X -> 0x%X; expected 0x11 -> 0x22", old, value)
+	}
+}
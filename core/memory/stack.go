@@ -1,10 +1,24 @@
 package memory
 
+// STACK_SIZE is the number of nested calls, Push and Pop do not check it.
+const STACK_SIZE = 16
+
 type Stack struct {
-	data [16]uint16
+	data [STACK_SIZE]uint16
 	SP   uint16 // Stack Pointer
 }
 
+// StackFrame is a subroutine call on the stack.
+type StackFrame struct {
+	Depth  int    // 1 for the first call
+	Return uint16 // Address the subroutine returns to
+}
+
+// Caller is the address of the call instruction.
+func (f StackFrame) Caller() uint16 {
+	return f.Return - 2
+}
+
 func (s *Stack) Push(addr uint16) {
 	s.data[s.SP] = addr
 	s.SP++
@@ -18,3 +32,25 @@ func (s *Stack) Pop() uint16 {
 
 	return addr
 }
+
+// Entries returns the return addresses on the stack, the last call last.
+func (s *Stack) Entries() []uint16 {
+	return append([]uint16(nil), s.data[:s.SP]...)
+}
+
+// Depth is the number of calls on the stack.
+func (s *Stack) Depth() int {
+	return int(s.SP)
+}
+
+// Frames returns a copy of the calls on the stack, the last call first as
+// debuggers list them.
+func (s *Stack) Frames() []StackFrame {
+	frames := make([]StackFrame, 0, s.SP)
+
+	for i := int(s.SP) - 1; i >= 0; i-- {
+		frames = append(frames, StackFrame{Depth: i + 1, Return: s.data[i]})
+	}
+
+	return frames
+}
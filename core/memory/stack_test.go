@@ -32,3 +32,43 @@ func TestStack(t *testing.T) {
 		t.Errorf("Stack.Pop() = %d; expected 0xBB", stackData)
 	}
 }
+
+func TestStackEntries(t *testing.T) {
+	stack := new(memory.Stack)
+
+	stack.Push(0x202)
+	stack.Push(0x304)
+
+	entries := stack.Entries()
+
+	if len(entries) != 2 || entries[0] != 0x202 || entries[1] != 0x304 {
+		t.Errorf("Stack.Entries() = %X; expected [202 304]", entries)
+	}
+
+	stack.Pop()
+
+	if entries[1] != 0x304 {
+		t.Errorf("entries[1] = 0x%X; expected a copy holding 0x304", entries[1])
+	}
+}
+
+func TestStackFrames(t *testing.T) {
+	stack := new(memory.Stack)
+
+	stack.Push(0x202)
+	stack.Push(0x304)
+
+	frames := stack.Frames()
+
+	if stack.Depth() != 2 || len(frames) != 2 {
+		t.Fatalf("Stack.Depth() = %d, %d frames; expected 2", stack.Depth(), len(frames))
+	}
+
+	if frames[0].Depth != 2 || frames[0].Return != 0x304 || frames[0].Caller() != 0x302 {
+		t.Errorf("frames[0] = %+v; expected the call at 0x302 first", frames[0])
+	}
+
+	if frames[1].Depth != 1 || frames[1].Caller() != 0x200 {
+		t.Errorf("frames[1] = %+v; expected the call at 0x200", frames[1])
+	}
+}
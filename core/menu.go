@@ -0,0 +1,228 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/gaoliveira21/chip8/core/render"
+	"github.com/gaoliveira21/chip8/core/ui"
+)
+
+const SAVE_SLOTS = 4
+
+// Instructions per frame offered by the menu.
+var SPEEDS = []int{1, 2, 5, 11, 20, 30, 50, 100, 200, 500, 1000, 3000}
+
+func (c8 *Chip8) newMenu() *ui.Menu {
+	items := []ui.Item{
+		{Label: ui.Text("RESUME"), Select: c8.closeMenu},
+		{Label: ui.Text("BROWSE ROMS"), Select: c8.openBrowser},
+		{Label: ui.Text("RESET"), Select: c8.reset},
+		{
+			Label:  func() string { return fmt.Sprintf("SAVE STATE  < SLOT %d >", c8.slot+1) },
+			Select: c8.saveState,
+			Adjust: c8.adjustSlot,
+		},
+		{
+			Label:  func() string { return fmt.Sprintf("LOAD STATE  < SLOT %d >", c8.slot+1) },
+			Select: c8.loadState,
+			Adjust: c8.adjustSlot,
+		},
+		{
+			Label:  func() string { return fmt.Sprintf("SPEED       < %d/FRAME >", c8.speed) },
+			Adjust: c8.adjustSpeed,
+		},
+		{
+			Label:  func() string { return fmt.Sprintf("PALETTE     < %s >", render.PaletteByName(c8.cfg.Palette).Name) },
+			Adjust: c8.adjustPalette,
+		},
+		c8.quirkItem("HALF SCROLL LORES", &c8.cfg.Quirks.HalfScrollLores),
+		c8.quirkItem("COLLISION ROWS   ", &c8.cfg.Quirks.CollisionRows),
+		c8.quirkItem("KEY PRESS ONLY   ", &c8.cfg.Quirks.KeyPressOnly),
+		{
+			Label:  func() string { return onOff("DEBUG OVERLAY    ", c8.debug) },
+			Adjust: func(int) { c8.debug = !c8.debug },
+		},
+		{Label: ui.Text("CONTROLS"), Select: c8.openRemap},
+	}
+
+	// Browsers can neither list directories nor close the page
+	if runtime.GOOS == "js" {
+		items = append(items[:1], items[2:]...)
+	} else {
+		items = append(items, ui.Item{Label: ui.Text("QUIT"), Select: func() { c8.quit = true }})
+	}
+
+	return &ui.Menu{Title: "CHIP-8", Items: items}
+}
+
+func (c8 *Chip8) openMenu() {
+	c8.menuOpen = true
+	c8.changed = false
+	c8.menu.Status = ""
+
+	if c8.rom == nil {
+		c8.menu.Status = "NO ROM LOADED"
+
+		if runtime.GOOS != "js" {
+			c8.openBrowser()
+		}
+	}
+}
+
+// closeMenu resumes the emulation and saves the settings changed in the
+// menu to the configuration file.
+func (c8 *Chip8) closeMenu() {
+	if c8.rom == nil {
+		return
+	}
+
+	c8.menuOpen = false
+
+	if !c8.changed || c8.cfg.Path == "" {
+		return
+	}
+
+	if err := c8.cfg.Save(c8.cfg.Path); err != nil {
+		log.Print(err)
+	}
+}
+
+func (c8 *Chip8) updateMenu() {
+	if c8.browser != nil {
+		c8.updateBrowser()
+		return
+	}
+
+	if c8.menu.Update() == ui.SCREEN_CANCELED {
+		c8.closeMenu()
+	}
+}
+
+func (c8 *Chip8) openBrowser() {
+	c8.browser = ui.NewBrowser(c8.cfg.Roms)
+}
+
+func (c8 *Chip8) updateBrowser() {
+	switch c8.browser.Update() {
+	case ui.SCREEN_DONE:
+		path := c8.browser.Selected
+		c8.browser = nil
+
+		rom, err := os.ReadFile(path)
+
+		if err == nil {
+			err = c8.load(rom, filepath.Base(path))
+		}
+
+		if err != nil {
+			c8.menu.Status = err.Error()
+			return
+		}
+
+		c8.closeMenu()
+	case ui.SCREEN_CANCELED:
+		c8.browser = nil
+	}
+}
+
+func (c8 *Chip8) openRemap() {
+	c8.closeMenu()
+	c8.remap = ui.NewRemap(c8.keyboard.Bindings, c8.gamepad.Bindings)
+}
+
+func (c8 *Chip8) reset() {
+	if c8.rom == nil {
+		return
+	}
+
+	states := c8.states
+
+	if err := c8.load(c8.rom, c8.title); err != nil {
+		c8.menu.Status = err.Error()
+		return
+	}
+
+	c8.states = states
+	c8.closeMenu()
+}
+
+func (c8 *Chip8) adjustSlot(delta int) {
+	c8.slot = (c8.slot + delta + SAVE_SLOTS) % SAVE_SLOTS
+}
+
+func (c8 *Chip8) saveState() {
+	c8.states[c8.slot] = c8.cpu.Clone()
+	c8.menu.Status = fmt.Sprintf("STATE SAVED TO SLOT %d", c8.slot+1)
+}
+
+func (c8 *Chip8) loadState() {
+	state := c8.states[c8.slot]
+
+	if state == nil {
+		c8.menu.Status = fmt.Sprintf("SLOT %d IS EMPTY", c8.slot+1)
+		return
+	}
+
+	// Clone again so the slot can be loaded more than once
+	c8.setCPU(state.Clone())
+	c8.closeMenu()
+}
+
+// adjustSpeed moves to the next speed of SPEEDS in the direction of delta.
+func (c8 *Chip8) adjustSpeed(delta int) {
+	i := 0
+
+	for i < len(SPEEDS)-1 && SPEEDS[i] < c8.speed {
+		i++
+	}
+
+	if delta < 0 || SPEEDS[i] == c8.speed {
+		i += delta
+	}
+
+	c8.speed = SPEEDS[max(0, min(i, len(SPEEDS)-1))]
+	c8.cfg.Speed = c8.speed
+	c8.changed = true
+}
+
+func (c8 *Chip8) adjustPalette(delta int) {
+	i := 0
+
+	for j, p := range render.Palettes {
+		if p.Name == c8.cfg.Palette {
+			i = j
+		}
+	}
+
+	p := render.Palettes[(i+delta+len(render.Palettes))%len(render.Palettes)]
+
+	c8.cfg.Palette = p.Name
+	c8.renderer.SetPalette(p)
+	c8.changed = true
+}
+
+// quirkItem toggles the configuration quirk q and applies it right away.
+func (c8 *Chip8) quirkItem(name string, q *bool) ui.Item {
+	toggle := func(int) {
+		*q = !*q
+		c8.applyQuirks()
+		c8.changed = true
+	}
+
+	return ui.Item{
+		Label:  func() string { return onOff(name, *q) },
+		Adjust: toggle,
+	}
+}
+
+func onOff(name string, on bool) string {
+	if on {
+		return fmt.Sprintf("%s < ON >", name)
+	}
+
+	return fmt.Sprintf("%s < OFF >", name)
+}
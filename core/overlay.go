@@ -0,0 +1,96 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gaoliveira21/chip8/core/cpu"
+	"github.com/gaoliveira21/chip8/core/disasm"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// OVERLAY_CALLS is how many stack frames and logged calls the debug overlay
+// shows.
+const OVERLAY_CALLS = 4
+
+// ipsCounter measures how many instructions run per second.
+type ipsCounter struct {
+	count int
+	start time.Time
+	ips   int
+}
+
+func (c *ipsCounter) add(n int) {
+	c.count += n
+
+	if elapsed := time.Since(c.start); elapsed >= time.Second {
+		c.ips = int(float64(c.count) / elapsed.Seconds())
+		c.count = 0
+		c.start = time.Now()
+	}
+}
+
+// debugLines describes the machine state for the debug overlay.
+func (c8 *Chip8) debugLines() []string {
+	c := c8.cpu
+	op := c.Opcode()
+	lines := []string{
+		fmt.Sprintf("PC %04X  I %04X", c.PC(), c.I()),
+		fmt.Sprintf("%04X %s%s", op, disasm.Mnemonic(op), c8.tools.Symbols.Comment(op)),
+	}
+
+	if name := c8.tools.Symbols.Name(c.PC()); name != "" {
+		lines[0] += "  " + name
+	}
+
+	for row := uint8(0); row < 16; row += 4 {
+		line := ""
+
+		for x := row; x < row+4; x++ {
+			line += fmt.Sprintf("V%X %02X ", x, c.V(x))
+		}
+
+		lines = append(lines, strings.TrimSpace(line))
+	}
+
+	stack := c.Stack()
+
+	lines = append(lines,
+		fmt.Sprintf("DT %02X  ST %02X", c.DelayTimer(), c.SoundTimer),
+		fmt.Sprintf("SP %X", stack.SP),
+	)
+
+	calls := disasm.CallStack(c, c8.tools.Symbols)
+
+	for i, call := range calls {
+		if i == OVERLAY_CALLS {
+			lines = append(lines, fmt.Sprintf("... %d MORE", len(calls)-i))
+			break
+		}
+
+		lines = append(lines, call.String())
+	}
+
+	lines = append(lines, fmt.Sprintf("IPS %d  FPS %.1f", c8.ips.ips, ebiten.ActualFPS()))
+
+	if c8.stop != nil {
+		var b *cpu.BreakError
+		lines = append(lines, c8.stop.Error())
+
+		if errors.As(c8.stop, &b) {
+			lines = append(lines, "F5 CONTINUE  F6 STEP")
+		} else {
+			lines = append(lines, "HALTED  RESET FROM THE MENU")
+		}
+
+		if c8.tools.Calls != nil {
+			for _, e := range c8.tools.Calls.Last(OVERLAY_CALLS) {
+				lines = append(lines, e.String())
+			}
+		}
+	}
+
+	return lines
+}
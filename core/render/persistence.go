@@ -0,0 +1,64 @@
+package render
+
+import "github.com/gaoliveira21/chip8/core/graphics"
+
+// Persistence emulates phosphor decay, a pixel turned off keeps glowing and
+// fades out over Frames frames, hiding the flicker of XOR drawn sprites.
+type Persistence struct {
+	Frames    int
+	intensity []float32
+	width     int
+	height    int
+	fading    int
+}
+
+func NewPersistence(frames int) *Persistence {
+	return &Persistence{Frames: frames}
+}
+
+// Update advances the decay by one frame using the current display content.
+func (p *Persistence) Update(g *graphics.Graphics) {
+	if p.width != g.Width || p.height != g.Height {
+		p.width = g.Width
+		p.height = g.Height
+		p.intensity = make([]float32, g.Width*g.Height)
+	}
+
+	decay := float32(1)
+
+	if p.Frames > 0 {
+		decay = 1 / float32(p.Frames)
+	}
+
+	p.fading = 0
+
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			i := y*g.Width + x
+
+			if g.GetPixel(y, x) == 0x01 {
+				p.intensity[i] = 1
+				continue
+			}
+
+			p.intensity[i] -= decay
+
+			if p.intensity[i] <= 0 {
+				p.intensity[i] = 0
+			} else {
+				p.fading++
+			}
+		}
+	}
+}
+
+// Fading reports whether any pixel is still fading out, the display looks
+// different in the next frame even when nothing was drawn.
+func (p *Persistence) Fading() bool {
+	return p.fading > 0
+}
+
+// Intensity returns how bright the pixel is, from 0 (off) to 1 (fully lit).
+func (p *Persistence) Intensity(y int, x int) float32 {
+	return p.intensity[y*p.width+x]
+}
@@ -0,0 +1,178 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/gaoliveira21/chip8/core/config"
+	"github.com/gaoliveira21/chip8/core/graphics"
+)
+
+const SCANLINE_BRIGHTNESS = 0.6
+
+var (
+	BackgroundColor = color.RGBA{23, 20, 33, 255}
+	ForegroundColor = color.RGBA{51, 209, 122, 255}
+)
+
+type Palette struct {
+	Name       string
+	Background color.RGBA
+	Foreground color.RGBA
+}
+
+var Palettes = []Palette{
+	{"green", BackgroundColor, ForegroundColor},
+	{"amber", color.RGBA{26, 16, 6, 255}, color.RGBA{255, 176, 0, 255}},
+	{"white", color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}},
+	{"lcd", color.RGBA{155, 188, 15, 255}, color.RGBA{15, 56, 15, 255}},
+	{"octo", color.RGBA{153, 102, 0, 255}, color.RGBA{255, 204, 0, 255}},
+}
+
+// PaletteByName returns the palette called name, or the first one when
+// there is none.
+func PaletteByName(name string) Palette {
+	for _, p := range Palettes {
+		if p.Name == name {
+			return p
+		}
+	}
+
+	return Palettes[0]
+}
+
+// Renderer draws the display on the CPU, the ebiten frontend uploads its
+// output as a single texture and applies the filter with a shader instead.
+type Renderer struct {
+	Scale       int
+	Filter      string
+	Background  color.RGBA
+	Foreground  color.RGBA
+	Zones       *graphics.ColorZones // CHIP-8X colors, replace Background and Foreground when set
+	persistence *Persistence
+	img         *image.RGBA
+	zones       uint64 // Zones version of img
+}
+
+func NewRenderer(scale int, effects config.Effects) *Renderer {
+	return &Renderer{
+		Scale:       scale,
+		Filter:      effects.Filter,
+		Background:  BackgroundColor,
+		Foreground:  ForegroundColor,
+		persistence: NewPersistence(effects.Persistence),
+	}
+}
+
+// SetPalette changes the display colors, the next Render redraws everything.
+func (r *Renderer) SetPalette(p Palette) {
+	r.Background = p.Background
+	r.Foreground = p.Foreground
+	r.img = nil
+}
+
+// Idle reports whether rendering g would return the same image as the
+// previous call, so frontends can skip uploading or encoding it.
+func (r *Renderer) Idle(g *graphics.Graphics) bool {
+	if r.Zones != nil && r.Zones.Version() != r.zones {
+		return false
+	}
+
+	return r.img != nil && !g.Dirty() && !r.persistence.Fading() && r.img.Bounds() == r.bounds(g)
+}
+
+func (r *Renderer) bounds(g *graphics.Graphics) image.Rectangle {
+	return image.Rect(0, 0, g.Width*r.Scale, g.Height*r.Scale)
+}
+
+// Render advances the persistence by one frame and returns the display
+// scaled by Scale with the configured filter applied, the returned image is
+// reused by the next call.
+func (r *Renderer) Render(g *graphics.Graphics) *image.RGBA {
+	if r.Idle(g) {
+		return r.img
+	}
+
+	r.persistence.Update(g)
+
+	if r.img == nil || r.img.Bounds() != r.bounds(g) {
+		r.img = image.NewRGBA(r.bounds(g))
+	}
+
+	img := r.img
+	bg := r.Background
+	fg := r.Foreground
+
+	if r.Zones != nil {
+		r.zones = r.Zones.Version()
+		bg = r.Zones.Background()
+	}
+
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			if r.Zones != nil {
+				fg = r.Zones.Foreground(y, x)
+			}
+
+			c := Mix(bg, fg, r.persistence.Intensity(y, x))
+
+			for sy := 0; sy < r.Scale; sy++ {
+				for sx := 0; sx < r.Scale; sx++ {
+					img.SetRGBA(x*r.Scale+sx, y*r.Scale+sy, c)
+				}
+			}
+		}
+	}
+
+	switch r.Filter {
+	case config.FILTER_SCANLINES:
+		scanlines(img)
+	case config.FILTER_CRT:
+		scanlines(img)
+		vignette(img)
+	}
+
+	return img
+}
+
+// Mix blends two colors, t = 0 returns a and t = 1 returns b.
+func Mix(a color.RGBA, b color.RGBA, t float32) color.RGBA {
+	lerp := func(x uint8, y uint8) uint8 {
+		return uint8(float32(x) + (float32(y)-float32(x))*t)
+	}
+
+	return color.RGBA{lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), lerp(a.A, b.A)}
+}
+
+func darken(c color.RGBA, f float64) color.RGBA {
+	return color.RGBA{uint8(float64(c.R) * f), uint8(float64(c.G) * f), uint8(float64(c.B) * f), c.A}
+}
+
+func scanlines(img *image.RGBA) {
+	b := img.Bounds()
+
+	for y := b.Min.Y + 1; y < b.Max.Y; y += 2 {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			img.SetRGBA(x, y, darken(img.RGBAAt(x, y), SCANLINE_BRIGHTNESS))
+		}
+	}
+}
+
+// vignette darkens the corners like the curved glass of a CRT, the barrel
+// distortion done by the shader is left out on the CPU path.
+func vignette(img *image.RGBA) {
+	b := img.Bounds()
+	w := float64(b.Dx())
+	h := float64(b.Dy())
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			u := (float64(x) + 0.5) / w
+			v := (float64(y) + 0.5) / h
+			f := math.Pow(16*u*v*(1-u)*(1-v), 0.15)
+
+			img.SetRGBA(x, y, darken(img.RGBAAt(x, y), f))
+		}
+	}
+}
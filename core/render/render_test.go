@@ -0,0 +1,183 @@
+package render_test
+
+import (
+	"testing"
+
+	"github.com/gaoliveira21/chip8/core/config"
+	"github.com/gaoliveira21/chip8/core/graphics"
+	"github.com/gaoliveira21/chip8/core/render"
+)
+
+func TestPersistenceDecay(t *testing.T) {
+	g := graphics.NewGraphics()
+	p := render.NewPersistence(4)
+
+	g.SetPixel(0, 0, 0x1)
+	p.Update(g)
+
+	if p.Intensity(0, 0) != 1 {
+		t.Errorf("Intensity(0, 0) = %f; expected 1", p.Intensity(0, 0))
+	}
+
+	g.SetPixel(0, 0, 0x0)
+	p.Update(g)
+
+	if p.Intensity(0, 0) != 0.75 {
+		t.Errorf("Intensity(0, 0) = %f; expected 0.75", p.Intensity(0, 0))
+	}
+
+	for i := 0; i < 3; i++ {
+		p.Update(g)
+	}
+
+	if p.Intensity(0, 0) != 0 {
+		t.Errorf("Intensity(0, 0) = %f; expected 0", p.Intensity(0, 0))
+	}
+}
+
+func TestPersistenceDisabled(t *testing.T) {
+	g := graphics.NewGraphics()
+	p := render.NewPersistence(0)
+
+	g.SetPixel(1, 1, 0x1)
+	p.Update(g)
+	g.SetPixel(1, 1, 0x0)
+	p.Update(g)
+
+	if p.Intensity(1, 1) != 0 {
+		t.Errorf("Intensity(1, 1) = %f; expected 0", p.Intensity(1, 1))
+	}
+}
+
+func TestRender(t *testing.T) {
+	g := graphics.NewGraphics()
+	g.SetPixel(0, 1, 0x1)
+
+	r := render.NewRenderer(2, config.Effects{})
+	img := r.Render(g)
+
+	if img.Bounds().Dx() != g.Width*2 || img.Bounds().Dy() != g.Height*2 {
+		t.Fatalf("image size = %v; expected %dx%d", img.Bounds().Size(), g.Width*2, g.Height*2)
+	}
+
+	if img.RGBAAt(3, 1) != render.ForegroundColor {
+		t.Errorf("img.RGBAAt(3, 1) = %v; expected %v", img.RGBAAt(3, 1), render.ForegroundColor)
+	}
+
+	if img.RGBAAt(0, 0) != render.BackgroundColor {
+		t.Errorf("img.RGBAAt(0, 0) = %v; expected %v", img.RGBAAt(0, 0), render.BackgroundColor)
+	}
+}
+
+func TestRenderScanlines(t *testing.T) {
+	g := graphics.NewGraphics()
+	g.SetPixel(0, 0, 0x1)
+
+	r := render.NewRenderer(2, config.Effects{Filter: config.FILTER_SCANLINES})
+	img := r.Render(g)
+
+	if img.RGBAAt(0, 0) != render.ForegroundColor {
+		t.Errorf("img.RGBAAt(0, 0) = %v; expected %v", img.RGBAAt(0, 0), render.ForegroundColor)
+	}
+
+	if img.RGBAAt(0, 1).G >= render.ForegroundColor.G {
+		t.Errorf("img.RGBAAt(0, 1) = %v; expected a darker line", img.RGBAAt(0, 1))
+	}
+}
+
+func TestRenderResolutionSwitch(t *testing.T) {
+	g := graphics.NewGraphics()
+	r := render.NewRenderer(1, config.Effects{Persistence: 4})
+
+	r.Render(g)
+	g.EnableHighResolutionMode()
+	img := r.Render(g)
+
+	if img.Bounds().Dx() != 0x80 || img.Bounds().Dy() != 0x40 {
+		t.Errorf("image size = %v; expected 128x64", img.Bounds().Size())
+	}
+}
+
+func TestRenderIdle(t *testing.T) {
+	g := graphics.NewGraphics()
+	r := render.NewRenderer(1, config.Effects{Persistence: 2})
+
+	g.SetPixel(0, 0, 0x1)
+	r.Render(g)
+	g.EndFrame()
+
+	if !r.Idle(g) {
+		t.Error("Renderer.Idle() = false; expected true")
+	}
+
+	g.SetPixel(0, 0, 0x0)
+
+	if r.Idle(g) {
+		t.Error("Renderer.Idle() = true after drawing; expected false")
+	}
+
+	r.Render(g)
+	g.EndFrame()
+
+	if r.Idle(g) {
+		t.Error("Renderer.Idle() = true while fading; expected false")
+	}
+
+	r.Render(g)
+
+	if !r.Idle(g) {
+		t.Error("Renderer.Idle() = false after fading out; expected true")
+	}
+}
+
+func TestRenderColorZones(t *testing.T) {
+	g := graphics.NewGraphics()
+	g.SetPixel(0, 9, 0x1)
+
+	r := render.NewRenderer(1, config.Effects{})
+	r.Zones = graphics.NewColorZones()
+	r.Zones.SetZones(0, 1, 1, 1, 4)
+
+	img := r.Render(g)
+
+	if img.RGBAAt(9, 0) != graphics.CHIP8X_COLORS[4] {
+		t.Errorf("img.RGBAAt(9, 0) = %v; expected %v", img.RGBAAt(9, 0), graphics.CHIP8X_COLORS[4])
+	}
+
+	if img.RGBAAt(0, 0) != r.Zones.Background() {
+		t.Errorf("img.RGBAAt(0, 0) = %v; expected %v", img.RGBAAt(0, 0), r.Zones.Background())
+	}
+
+	g.EndFrame()
+	r.Zones.StepBackground()
+
+	if r.Idle(g) {
+		t.Error("Renderer.Idle() = true after a color change; expected false")
+	}
+}
+
+func TestSetPalette(t *testing.T) {
+	g := graphics.NewGraphics()
+	g.SetPixel(0, 0, 0x01)
+
+	r := render.NewRenderer(1, config.Effects{})
+	r.Render(g)
+	g.EndFrame()
+
+	p := render.PaletteByName("amber")
+	r.SetPalette(p)
+
+	if r.Idle(g) {
+		t.Errorf("r.Idle(g) = true; expected false after a palette change")
+	}
+
+	img := r.Render(g)
+
+	if c := img.RGBAAt(0, 0); c != p.Foreground {
+		t.Errorf("img.RGBAAt(0, 0) = %v; expected %v", c, p.Foreground)
+	}
+
+	if c := img.RGBAAt(1, 0); c != p.Background {
+		t.Errorf("img.RGBAAt(1, 0) = %v; expected %v", c, p.Background)
+	}
+}
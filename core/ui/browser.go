@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Browser lists a directory to pick a ROM from, Enter opens directories
+// and chooses files, Escape goes back.
+type Browser struct {
+	Dir      string
+	Selected string // Path of the chosen file once Update returns SCREEN_DONE
+	entries  []os.DirEntry
+	err      error
+	cursor   int
+	offset   int // First entry shown
+}
+
+func NewBrowser(dir string) *Browser {
+	b := &Browser{}
+	b.open(dir)
+
+	return b
+}
+
+// open lists dir, directories first, hidden files are skipped.
+func (b *Browser) open(dir string) {
+	b.Dir = filepath.Clean(dir)
+	b.cursor = 0
+	b.offset = 0
+	b.entries = nil
+
+	entries, err := os.ReadDir(b.Dir)
+	b.err = err
+
+	for _, e := range entries {
+		if e.Name()[0] != '.' {
+			b.entries = append(b.entries, e)
+		}
+	}
+
+	sort.SliceStable(b.entries, func(i, j int) bool {
+		return b.entries[i].IsDir() && !b.entries[j].IsDir()
+	})
+}
+
+// names returns the entries as shown, the parent directory first.
+func (b *Browser) names() []string {
+	names := []string{"../"}
+
+	for _, e := range b.entries {
+		if e.IsDir() {
+			names = append(names, e.Name()+"/")
+		} else {
+			names = append(names, e.Name())
+		}
+	}
+
+	return names
+}
+
+func (b *Browser) Update() int {
+	if inpututil.IsKeyJustPressed(MENU_KEY) {
+		return SCREEN_CANCELED
+	}
+
+	count := len(b.entries) + 1
+
+	switch {
+	case repeated(ebiten.KeyArrowUp):
+		b.cursor = (b.cursor + count - 1) % count
+	case repeated(ebiten.KeyArrowDown):
+		b.cursor = (b.cursor + 1) % count
+	}
+
+	if !selected() {
+		return SCREEN_ACTIVE
+	}
+
+	if b.cursor == 0 {
+		b.open(filepath.Join(b.Dir, ".."))
+		return SCREEN_ACTIVE
+	}
+
+	e := b.entries[b.cursor-1]
+	path := filepath.Join(b.Dir, e.Name())
+
+	if e.IsDir() {
+		b.open(path)
+		return SCREEN_ACTIVE
+	}
+
+	b.Selected = path
+
+	return SCREEN_DONE
+}
+
+func (b *Browser) Draw(screen *ebiten.Image) {
+	dim(screen)
+
+	printAt(screen, b.Dir, 0, 0, HighlightColor)
+
+	if b.err != nil {
+		printAt(screen, b.err.Error(), 2, 0, TextColor)
+		return
+	}
+
+	visible := max(1, lines(screen)-2)
+
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+
+	if b.cursor >= b.offset+visible {
+		b.offset = b.cursor - visible + 1
+	}
+
+	names := b.names()
+
+	for i := b.offset; i < len(names) && i < b.offset+visible; i++ {
+		c := TextColor
+		marker := "  "
+
+		if i == b.cursor {
+			c = HighlightColor
+			marker = "> "
+		}
+
+		printAt(screen, marker+names[i], 2+i-b.offset, 0, c)
+	}
+}
@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/gaoliveira21/chip8/core/memory"
+	"github.com/gaoliveira21/chip8/core/render"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// MEMORY_KEY shows and hides the memory viewer.
+const MEMORY_KEY = ebiten.KeyF4
+
+const (
+	MEMORY_COLUMNS   = 8    // Bytes per row of the hex grid
+	MEMORY_BANK      = 4096 // Bytes shown by the overview, 64x64 pixels
+	MEMORY_HEAT_SIZE = 0x10000
+	SPRITE_ROWS      = 16
+)
+
+var (
+	ReadColor    = color.RGBA{0x62, 0xA0, 0xEA, 0xFF}
+	WriteColor   = color.RGBA{0xE0, 0x1B, 0x24, 0xFF}
+	ExecuteColor = HighlightColor
+	UnusedColor  = color.RGBA{0x3D, 0x38, 0x46, 0xFF}
+)
+
+// MemoryViewer shows the memory as a hex grid and an overview of the 4 KB
+// around it, bytes recently written, executed or read are colored in this
+// order of priority. PageUp and PageDown scroll the grid.
+type MemoryViewer struct {
+	Heat     *memory.Heat
+	offset   int // First row shown
+	rows     int // Rows that fit on the screen
+	overview *ebiten.Image
+	sprite   *ebiten.Image
+	pix      []byte
+}
+
+// NewMemoryViewer tracks the accesses to the first 64 KB of a memory of
+// size bytes, install Heat.Record as the Recorder of the MMU.
+func NewMemoryViewer(size int) *MemoryViewer {
+	return &MemoryViewer{
+		Heat: memory.NewHeat(min(size, MEMORY_HEAT_SIZE)),
+		rows: 1,
+	}
+}
+
+// Update scrolls the grid and fades the accesses, once per frame.
+func (v *MemoryViewer) Update() {
+	last := max(0, v.Heat.Size()/MEMORY_COLUMNS-v.rows)
+
+	switch {
+	case repeated(ebiten.KeyPageUp):
+		v.offset -= v.rows
+	case repeated(ebiten.KeyPageDown):
+		v.offset += v.rows
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		v.offset = 0
+	}
+
+	v.offset = max(0, min(v.offset, last))
+	v.Heat.Cool()
+}
+
+// color returns the color of the byte at addr, fading with the age of its
+// last access.
+func (v *MemoryViewer) color(addr int, base color.RGBA) color.RGBA {
+	kinds := []struct {
+		kind int
+		c    color.RGBA
+	}{
+		{memory.ACCESS_WRITE, WriteColor},
+		{memory.ACCESS_EXECUTE, ExecuteColor},
+		{memory.ACCESS_READ, ReadColor},
+	}
+
+	for _, k := range kinds {
+		if h := v.Heat.Get(k.kind, addr); h > 0 {
+			return render.Mix(base, k.c, 0.3+0.7*float32(h)/memory.HEAT_FRAMES)
+		}
+	}
+
+	return base
+}
+
+func (v *MemoryViewer) Draw(screen *ebiten.Image, mmu *memory.MMU, pc uint16, i uint32) {
+	dim(screen)
+
+	v.rows = max(1, lines(screen)-2)
+	size := min(mmu.Size(), v.Heat.Size())
+
+	printAt(screen, fmt.Sprintf("MEMORY  PC %04X  I %04X", pc, i), 0, 0, TextColor)
+	printAt(screen, "READ", 0, 26, ReadColor)
+	printAt(screen, "WRITE", 0, 31, WriteColor)
+	printAt(screen, "EXEC", 0, 37, ExecuteColor)
+
+	for row := 0; row < v.rows; row++ {
+		addr := (v.offset + row) * MEMORY_COLUMNS
+
+		if addr >= size {
+			break
+		}
+
+		printAt(screen, fmt.Sprintf("%04X:", addr), 2+row, 0, TextColor)
+
+		for col := 0; col < MEMORY_COLUMNS && addr+col < size; col++ {
+			b := mmu.Peek(uint32(addr + col))
+			printAt(screen, fmt.Sprintf("%02X", b), 2+row, 6+col*3, v.color(addr+col, TextColor))
+		}
+	}
+
+	scale := TextScale(screen)
+	x := (MARGIN + (7+MEMORY_COLUMNS*3)*GLYPH_ADVANCE) * scale
+	y := (MARGIN + 2*LINE_ADVANCE) * scale
+	bank := v.offset * MEMORY_COLUMNS / MEMORY_BANK * MEMORY_BANK
+
+	v.drawOverview(screen, mmu, bank, size, x, y, scale)
+
+	y += (64 + LINE_ADVANCE) * scale
+	DrawText(screen, "SPRITE AT I", x, y, scale, TextColor)
+
+	y += LINE_ADVANCE * scale
+	v.drawSprite(screen, mmu, i, x, y, scale*2)
+}
+
+// drawOverview draws the 4 KB starting at bank as 64x64 pixels, one per
+// byte.
+func (v *MemoryViewer) drawOverview(screen *ebiten.Image, mmu *memory.MMU, bank int, size int, x int, y int, scale int) {
+	if v.overview == nil {
+		v.overview = ebiten.NewImage(64, 64)
+		v.pix = make([]byte, 4*MEMORY_BANK)
+	}
+
+	for i := 0; i < MEMORY_BANK; i++ {
+		c := color.RGBA{0x00, 0x00, 0x00, 0xFF}
+
+		if addr := bank + i; addr < size {
+			if mmu.Peek(uint32(addr)) != 0x00 {
+				c = UnusedColor
+			}
+
+			c = v.color(addr, c)
+		}
+
+		copy(v.pix[4*i:], []byte{c.R, c.G, c.B, c.A})
+	}
+
+	v.overview.WritePixels(v.pix)
+
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Scale(float64(scale), float64(scale))
+	opts.GeoM.Translate(float64(x), float64(y))
+
+	screen.DrawImage(v.overview, opts)
+}
+
+// drawSprite draws SPRITE_ROWS bytes at I as 8 pixels wide rows.
+func (v *MemoryViewer) drawSprite(screen *ebiten.Image, mmu *memory.MMU, i uint32, x int, y int, scale int) {
+	if v.sprite == nil {
+		v.sprite = ebiten.NewImage(8, SPRITE_ROWS)
+	}
+
+	pix := make([]byte, 4*8*SPRITE_ROWS)
+
+	for row := 0; row < SPRITE_ROWS; row++ {
+		var b byte
+
+		if addr := int(i) + row; addr < mmu.Size() {
+			b = mmu.Peek(uint32(addr))
+		}
+
+		for col := 0; col < 8; col++ {
+			c := render.BackgroundColor
+
+			if (b>>(7-col))&0x01 == 0x01 {
+				c = render.ForegroundColor
+			}
+
+			copy(pix[4*(row*8+col):], []byte{c.R, c.G, c.B, c.A})
+		}
+	}
+
+	v.sprite.WritePixels(pix)
+
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Scale(float64(scale), float64(scale))
+	opts.GeoM.Translate(float64(x), float64(y))
+
+	screen.DrawImage(v.sprite, opts)
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// MENU_KEY opens and closes the menu.
+const MENU_KEY = ebiten.KeyEscape
+
+// Item is an entry of the menu, its label is read every frame so settings
+// show their current value.
+type Item struct {
+	Label  func() string
+	Select func()          // Enter or Space, nil to change the setting with Adjust
+	Adjust func(delta int) // Left and right arrows, nil for actions
+}
+
+// Text returns a label that never changes.
+func Text(s string) func() string {
+	return func() string { return s }
+}
+
+// Menu is a list of actions and settings, Up and Down move the cursor and
+// Escape closes it.
+type Menu struct {
+	Title  string
+	Items  []Item
+	Status string // Result of the last action, shown below the items
+	cursor int
+}
+
+func (m *Menu) Update() int {
+	if inpututil.IsKeyJustPressed(MENU_KEY) {
+		return SCREEN_CANCELED
+	}
+
+	switch {
+	case repeated(ebiten.KeyArrowUp):
+		m.cursor = (m.cursor + len(m.Items) - 1) % len(m.Items)
+	case repeated(ebiten.KeyArrowDown):
+		m.cursor = (m.cursor + 1) % len(m.Items)
+	}
+
+	item := m.Items[m.cursor]
+
+	if item.Adjust != nil {
+		switch {
+		case repeated(ebiten.KeyArrowLeft):
+			item.Adjust(-1)
+		case repeated(ebiten.KeyArrowRight):
+			item.Adjust(1)
+		}
+	}
+
+	if selected() {
+		m.Status = ""
+
+		if item.Select != nil {
+			item.Select()
+		} else if item.Adjust != nil {
+			item.Adjust(1)
+		}
+	}
+
+	return SCREEN_ACTIVE
+}
+
+func (m *Menu) Draw(screen *ebiten.Image) {
+	dim(screen)
+
+	printAt(screen, m.Title, 0, 0, HighlightColor)
+
+	for i, item := range m.Items {
+		c := TextColor
+		marker := "  "
+
+		if i == m.cursor {
+			c = HighlightColor
+			marker = "> "
+		}
+
+		printAt(screen, marker+item.Label(), 2+i, 0, c)
+	}
+
+	printAt(screen, m.Status, 3+len(m.Items), 0, TextColor)
+}
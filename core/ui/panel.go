@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	DEBUG_KEY    = ebiten.KeyF3 // Shows and hides the debug overlay
+	CONTINUE_KEY = ebiten.KeyF5 // Resumes a machine stopped at a breakpoint
+	STEP_KEY     = ebiten.KeyF6 // Runs one instruction of a machine stopped at a breakpoint
+)
+
+// DrawPanel draws lines of text over a dark box in the top left corner,
+// leaving the rest of the screen visible.
+func DrawPanel(screen *ebiten.Image, lines []string) {
+	scale := TextScale(screen)
+	width := 0
+
+	for _, l := range lines {
+		width = max(width, TextWidth(l, scale))
+	}
+
+	w := width + 2*MARGIN*scale
+	h := (len(lines)*LINE_ADVANCE + 2*MARGIN) * scale
+
+	vector.DrawFilledRect(screen, 0, 0, float32(w), float32(h), OverlayColor, false)
+
+	for i, l := range lines {
+		printAt(screen, l, i, 0, TextColor)
+	}
+}
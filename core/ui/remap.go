@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gaoliveira21/chip8/core/input"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// REMAP_KEY opens the remapping screen.
+const REMAP_KEY = ebiten.KeyF1
+
+// Remap is the screen that rebinds the keypad, it walks the CHIP-8 keys in
+// keypad order and binds each one to the next key or gamepad button pressed.
+type Remap struct {
+	Keyboard map[ebiten.Key]uint8
+	Gamepad  map[ebiten.StandardGamepadButton]uint8
+	step     int
+
+	keys    []ebiten.Key
+	ids     []ebiten.GamepadID
+	buttons []ebiten.StandardGamepadButton
+}
+
+// NewRemap starts remapping from copies of the current bindings, they are
+// only applied by the caller once the screen is done.
+func NewRemap(keyboard map[ebiten.Key]uint8, gamepad map[ebiten.StandardGamepadButton]uint8) *Remap {
+	r := &Remap{
+		Keyboard: map[ebiten.Key]uint8{},
+		Gamepad:  map[ebiten.StandardGamepadButton]uint8{},
+	}
+
+	for key, value := range keyboard {
+		r.Keyboard[key] = value
+	}
+
+	for button, value := range gamepad {
+		r.Gamepad[button] = value
+	}
+
+	return r
+}
+
+// Update reads the input of this frame and returns the state of the screen,
+// Backspace keeps the current binding and Escape discards every change.
+func (r *Remap) Update() int {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return SCREEN_CANCELED
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) {
+		return r.next()
+	}
+
+	value := Keypad[r.step]
+	r.keys = inpututil.AppendJustPressedKeys(r.keys[:0])
+
+	for _, key := range r.keys {
+		if key == REMAP_KEY {
+			continue
+		}
+
+		for k, v := range r.Keyboard {
+			if v == value {
+				delete(r.Keyboard, k)
+			}
+		}
+
+		r.Keyboard[key] = value
+		return r.next()
+	}
+
+	r.ids = ebiten.AppendGamepadIDs(r.ids[:0])
+
+	for _, id := range r.ids {
+		r.buttons = inpututil.AppendJustPressedStandardGamepadButtons(id, r.buttons[:0])
+
+		for _, button := range r.buttons {
+			for b, v := range r.Gamepad {
+				if v == value {
+					delete(r.Gamepad, b)
+				}
+			}
+
+			r.Gamepad[button] = value
+			return r.next()
+		}
+	}
+
+	return SCREEN_ACTIVE
+}
+
+func (r *Remap) next() int {
+	r.step++
+
+	if r.step == len(Keypad) {
+		return SCREEN_DONE
+	}
+
+	return SCREEN_ACTIVE
+}
+
+func (r *Remap) Draw(screen *ebiten.Image) {
+	dim(screen)
+
+	printAt(screen, "REMAP CONTROLS", 0, 0, HighlightColor)
+	printAt(screen, fmt.Sprintf("Press a key or gamepad button for key %X", Keypad[r.step]), 2, 0, TextColor)
+	printAt(screen, "Backspace keeps the binding, Esc cancels", 3, 0, TextColor)
+
+	for i, value := range Keypad {
+		c := TextColor
+
+		if i == r.step {
+			c = HighlightColor
+		}
+
+		printAt(screen, fmt.Sprintf("%X: %s", value, r.names(value)), 5+i, 0, c)
+	}
+}
+
+// names lists the keys and buttons bound to value.
+func (r *Remap) names(value uint8) string {
+	names := []string{}
+
+	for key, v := range r.Keyboard {
+		if v == value {
+			names = append(names, key.String())
+		}
+	}
+
+	for button, v := range r.Gamepad {
+		if v == value {
+			names = append(names, "Pad "+input.GamepadButtonName(button))
+		}
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/gaoliveira21/chip8/core/font"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	GLYPH_ADVANCE = font.FONT_WIDTH + 1  // Font pixels between two characters
+	LINE_ADVANCE  = font.FONT_HEIGHT + 3 // Font pixels between two lines
+)
+
+var glyphs = map[rune]*ebiten.Image{}
+
+// glyph returns the white image of the font sprite of r, created on first use.
+func glyph(r rune) *ebiten.Image {
+	if img, ok := glyphs[r]; ok {
+		return img
+	}
+
+	sprite := font.Glyph(r)
+	pix := make([]byte, 4*font.FONT_WIDTH*font.FONT_HEIGHT)
+
+	for row := 0; row < font.FONT_HEIGHT; row++ {
+		for col := 0; col < font.FONT_WIDTH; col++ {
+			if (sprite[row]>>(7-col))&0x01 == 0x00 {
+				continue
+			}
+
+			i := 4 * (row*font.FONT_WIDTH + col)
+			copy(pix[i:i+4], []byte{0xFF, 0xFF, 0xFF, 0xFF})
+		}
+	}
+
+	img := ebiten.NewImage(font.FONT_WIDTH, font.FONT_HEIGHT)
+	img.WritePixels(pix)
+	glyphs[r] = img
+
+	return img
+}
+
+// DrawText draws s with the CHIP-8 font, its top left corner at (x, y) and
+// every font pixel scale screen pixels wide.
+func DrawText(screen *ebiten.Image, s string, x int, y int, scale int, c color.Color) {
+	for i, r := range []rune(s) {
+		opts := &ebiten.DrawImageOptions{}
+		opts.GeoM.Scale(float64(scale), float64(scale))
+		opts.GeoM.Translate(float64(x+i*GLYPH_ADVANCE*scale), float64(y))
+		opts.ColorScale.ScaleWithColor(c)
+
+		screen.DrawImage(glyph(r), opts)
+	}
+}
+
+func TextWidth(s string, scale int) int {
+	return (len([]rune(s))*GLYPH_ADVANCE - 1) * scale
+}
+
+// TextScale returns the font scale for screen, text stays readable on small
+// and large windows.
+func TextScale(screen *ebiten.Image) int {
+	size := min(screen.Bounds().Dx(), screen.Bounds().Dy())
+
+	return max(1, size/160)
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/gaoliveira21/chip8/core/font"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var (
+	KeyColor        = color.RGBA{0x3D, 0x38, 0x46, 0xFF}
+	KeyPressedColor = color.RGBA{0x33, 0xD1, 0x7A, 0xFF}
+	KeyLabelColor   = color.RGBA{0xF6, 0xF5, 0xF4, 0xFF}
+)
+
+// TouchKeypad is an on-screen 4x4 keypad for touchscreens, it shows up with
+// the first touch and sits below the display in portrait and on its right in
+// landscape. Every finger on a key holds it.
+type TouchKeypad struct {
+	Active  bool
+	display image.Rectangle // Screen area left for the display
+	keys    [16]image.Rectangle
+	pressed [16]uint8
+	touches []ebiten.TouchID
+}
+
+func NewTouchKeypad() *TouchKeypad {
+	return &TouchKeypad{}
+}
+
+// Resize lays the keypad out for a screen of w x h pixels.
+func (t *TouchKeypad) Resize(w int, h int) {
+	var pad image.Rectangle
+
+	if h > w {
+		size := min(w, h/2)
+		pad = image.Rect((w-size)/2, h-size, (w+size)/2, h)
+		t.display = image.Rect(0, 0, w, h-size)
+	} else {
+		size := min(h, w*2/5)
+		pad = image.Rect(w-size, (h-size)/2, w, (h+size)/2)
+		t.display = image.Rect(0, 0, w-size, h)
+	}
+
+	gap := pad.Dx() / 40
+	size := pad.Dx() / 4
+
+	for i := range Keypad {
+		x := pad.Min.X + i%4*size
+		y := pad.Min.Y + i/4*size
+
+		t.keys[i] = image.Rect(x+gap, y+gap, x+size-gap, y+size-gap)
+	}
+}
+
+// Display returns the screen area left for the display.
+func (t *TouchKeypad) Display(screen image.Rectangle) image.Rectangle {
+	if !t.Active {
+		return screen
+	}
+
+	return t.display
+}
+
+func (t *TouchKeypad) Read(keys []uint8) {
+	t.touches = ebiten.AppendTouchIDs(t.touches[:0])
+
+	if len(t.touches) > 0 {
+		t.Active = true
+	}
+
+	t.pressed = [16]uint8{}
+
+	for _, id := range t.touches {
+		p := image.Pt(ebiten.TouchPosition(id))
+
+		for i, r := range t.keys {
+			if p.In(r) {
+				t.pressed[Keypad[i]] = 0x01
+				keys[Keypad[i]] = 0x01
+			}
+		}
+	}
+}
+
+func (t *TouchKeypad) Draw(screen *ebiten.Image) {
+	if !t.Active {
+		return
+	}
+
+	for i, r := range t.keys {
+		value := Keypad[i]
+		c := KeyColor
+
+		if t.pressed[value] == 0x01 {
+			c = KeyPressedColor
+		}
+
+		vector.DrawFilledRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), c, false)
+		label := fmt.Sprintf("%X", value)
+		scale := max(1, r.Dy()/2/font.FONT_HEIGHT)
+		x := r.Min.X + (r.Dx()-TextWidth(label, scale))/2
+		y := r.Min.Y + (r.Dy()-font.FONT_HEIGHT*scale)/2
+
+		DrawText(screen, label, x, y, scale, KeyLabelColor)
+	}
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const MARGIN = 4 // Font pixels around the overlay text
+
+// States returned by the Update method of the screens.
+const (
+	SCREEN_ACTIVE = iota
+	SCREEN_DONE
+	SCREEN_CANCELED
+)
+
+var (
+	OverlayColor   = color.RGBA{0x00, 0x00, 0x00, 0xC0}
+	TextColor      = color.RGBA{0xF6, 0xF5, 0xF4, 0xFF}
+	HighlightColor = color.RGBA{0x33, 0xD1, 0x7A, 0xFF}
+)
+
+// Keypad is the layout of the COSMAC VIP keypad, row by row.
+var Keypad = [16]uint8{
+	0x01, 0x02, 0x03, 0x0C,
+	0x04, 0x05, 0x06, 0x0D,
+	0x07, 0x08, 0x09, 0x0E,
+	0x0A, 0x00, 0x0B, 0x0F,
+}
+
+// dim darkens the screen so the text drawn over the emulator is readable.
+func dim(screen *ebiten.Image) {
+	w := float32(screen.Bounds().Dx())
+	h := float32(screen.Bounds().Dy())
+
+	vector.DrawFilledRect(screen, 0, 0, w, h, OverlayColor, false)
+}
+
+// printAt draws text at the given line and column of the overlay.
+func printAt(screen *ebiten.Image, text string, line int, column int, c color.Color) {
+	scale := TextScale(screen)
+	x := (MARGIN + column*GLYPH_ADVANCE) * scale
+	y := (MARGIN + line*LINE_ADVANCE) * scale
+
+	DrawText(screen, text, x, y, scale, c)
+}
+
+// lines returns how many lines of text fit on screen.
+func lines(screen *ebiten.Image) int {
+	return (screen.Bounds().Dy()/TextScale(screen) - 2*MARGIN) / LINE_ADVANCE
+}
+
+// repeated reports whether key was just pressed or is held long enough to
+// repeat, for moving through lists.
+func repeated(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+
+	return d == 1 || (d >= 20 && d%4 == 0)
+}
+
+// selected reports whether the highlighted entry was chosen.
+func selected() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace)
+}
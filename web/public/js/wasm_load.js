@@ -21,7 +21,7 @@ form.addEventListener("submit", (e) => {
 
       canvas.addEventListener("keydown", (e) => {
         console.log(e.key)
-          if (e.key == "Escape") {
+          if (e.key == "F2") {
             location.reload()
           }
         })
@@ -7,6 +7,7 @@ import (
 	"syscall/js"
 
 	"github.com/gaoliveira21/chip8/core"
+	"github.com/gaoliveira21/chip8/core/config"
 	"github.com/gaoliveira21/chip8/web/http"
 )
 
@@ -23,5 +24,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	core.RunChip8(rom, "[CHIP-8] - "+romName)
+	if err := core.RunChip8(rom, "[CHIP-8] - "+romName, config.Default(), core.DebugOptions{}); err != nil {
+		log.Fatal(err)
+	}
 }